@@ -46,8 +46,56 @@ type AIDeploymentSpec struct {
 	Ingress Ingress `json:"ingress,omitempty"`
 
 	Models []AIModel `json:"models,omitempty"`
+
+	// When the tags of the rendered images are resolved to digests again.
+	// Defaults to OnSpecChange.
+	// +optional
+	ImageUpdatePolicy ImageUpdatePolicy `json:"imageUpdatePolicy,omitempty"`
+
+	// What the engine is run as. Defaults to deployment, a Deployment with a
+	// Service and an Ingress for the endpoints.
+	// +optional
+	Workload Workload `json:"workload,omitempty"`
+
+	// Splits the model across the GPUs of several nodes, for engines which
+	// support it
+	// +optional
+	Distributed *Distributed `json:"distributed,omitempty"`
 }
 
+type Distributed struct {
+	// The number of nodes, including the leader
+	// +kubebuilder:validation:Minimum=2
+	Nodes int32 `json:"nodes"`
+	// The number of GPUs used on each node
+	// +kubebuilder:validation:Minimum=1
+	GPUsPerNode int32 `json:"gpusPerNode"`
+}
+
+// +kubebuilder:validation:Enum=deployment;kserve
+type Workload string
+
+const (
+	WorkloadDeployment Workload = "deployment"
+	// A KServe InferenceService with a custom predictor, KServe creates the
+	// routes so the endpoints are ignored
+	WorkloadKServe Workload = "kserve"
+)
+
+// +kubebuilder:validation:Enum=OnSpecChange;Always;Never
+type ImageUpdatePolicy string
+
+const (
+	// Tags are resolved once for each generation of the spec, so every
+	// replica runs the same build until the AIDeployment is changed
+	ImageUpdatePolicyOnSpecChange ImageUpdatePolicy = "OnSpecChange"
+	// Tags are resolved on every reconcile, new builds are rolled out when
+	// they are pushed
+	ImageUpdatePolicyAlways ImageUpdatePolicy = "Always"
+	// Tags are not resolved and the images are rendered as they are
+	ImageUpdatePolicyNever ImageUpdatePolicy = "Never"
+)
+
 type Service struct {
 	// +optional
 	Labels      map[string]string `json:"labels,omitempty"`
@@ -92,16 +140,37 @@ type Accelerator struct {
 	MinVersion *Version `json:"minVersion,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=Deployment;StatefulSet
+type DeploymentKind string
+
+const (
+	DeploymentKindDeployment DeploymentKind = "Deployment"
+	// Each replica keeps its models on a PersistentVolumeClaim, so they are
+	// not downloaded again when the pod restarts
+	DeploymentKindStatefulSet DeploymentKind = "StatefulSet"
+)
+
 type Deployment struct {
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 
+	// The kind of workload the engine is run as. Defaults to Deployment.
+	// +optional
+	Kind DeploymentKind `json:"kind,omitempty"`
+
+	// The claim made for each replica's models volume when the kind is
+	// StatefulSet. It must request enough storage for the models.
+	// +optional
+	VolumeClaimTemplate *v1.PersistentVolumeClaimSpec `json:"volumeClaimTemplate,omitempty"`
+
 	// What kind of interface (e.g. CUDA) the accelerator hardware
 	// should support.
 	// +optional
 	Accelerator *Accelerator `json:"accelerator,omitempty"`
 
-	// The deployment must request the minimum amount of memory required by the models
+	// The deployment must request the minimum amount of memory required by the models.
+	// The resources are for each pod, they are split between the containers of an
+	// engine which runs a container for each model.
 	// +optional
 	Resources v1.ResourceRequirements `json:"resources,omitempty"`
 
@@ -113,6 +182,11 @@ type Deployment struct {
 	// +optional
 	PodTemplate *v1.PodTemplateSpec `json:"template,omitempty"`
 
+	// The secrets used to pull the engine's images. They are used instead of
+	// the operator config's, the pod template's take precedence over them.
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// +optional
 	StartupProbe *Probe `json:"startupProbe,omitempty"`
 	// +optional
@@ -143,12 +217,195 @@ const (
 	AIEngineNameGeneric      AIEngineName = "generic"
 	AIEngineNameDeepSpeedMii AIEngineName = "deepspeed-mii"
 	AIEngineNameTriton       AIEngineName = "triton"
+	AIEngineNameTGI          AIEngineName = "tgi"
+	AIEngineNameOllama       AIEngineName = "ollama"
+	AIEngineNameLlamaCpp     AIEngineName = "llamacpp"
+	AIEngineNameSGLang       AIEngineName = "sglang"
+	AIEngineNameTEI          AIEngineName = "tei"
+	AIEngineNameWhisper      AIEngineName = "whisper"
+	// The engine is described by an AIEngineTemplate
+	AIEngineNameTemplate AIEngineName = "template"
 )
 
 type AIEngine struct {
 	Name AIEngineName `json:"name"`
+	// Free form options read by the engine, keys the engine doesn't know
+	// are reported in the status warnings
 	// +optional
 	Options map[string]string `json:"options,omitempty"`
+	// Typed options for the vllm engine, these take precedence over Options
+	// +optional
+	VLLM *VLLMOptions `json:"vllm,omitempty"`
+	// Typed options for the deepspeed-mii engine, these take precedence over
+	// Options
+	// +optional
+	DeepSpeedMii *DeepSpeedMiiOptions `json:"deepspeedMii,omitempty"`
+	// Typed options for the tgi engine, these take precedence over Options
+	// +optional
+	TGI *TGIOptions `json:"tgi,omitempty"`
+	// Typed options for the sglang engine, these take precedence over Options
+	// +optional
+	SGLang *SGLangOptions `json:"sglang,omitempty"`
+	// Typed options for the llamacpp engine, these take precedence over
+	// Options
+	// +optional
+	LlamaCpp *LlamaCppOptions `json:"llamacpp,omitempty"`
+	// Typed options for the tei engine, these take precedence over Options
+	// +optional
+	TEI *TEIOptions `json:"tei,omitempty"`
+	// Typed options for the whisper engine, these take precedence over
+	// Options
+	// +optional
+	Whisper *WhisperOptions `json:"whisper,omitempty"`
+	// The engine's image, read by every engine. It takes precedence over the
+	// imageRepository and imageTag options and the operator config.
+	// +optional
+	Image *EngineImage `json:"image,omitempty"`
+	// The AIEngineTemplate used when the name is template
+	// +optional
+	TemplateRef *AIEngineTemplateReference `json:"templateRef,omitempty"`
+}
+
+type VLLMOptions struct {
+	// The data type of the weights and activations, --dtype
+	// +kubebuilder:validation:Enum=auto;half;float16;bfloat16;float;float32
+	// +optional
+	Dtype string `json:"dtype,omitempty"`
+	// The quantization method the weights were prepared with, --quantization
+	// +kubebuilder:validation:Enum=aqlm;awq;awq_marlin;bitsandbytes;compressed-tensors;deepspeedfp;experts_int8;fbgemm_fp8;fp8;gguf;gptq;gptq_marlin;gptq_marlin_24;marlin;squeezellm
+	// +optional
+	Quantization string `json:"quantization,omitempty"`
+	// The number of GPUs the model is split across, --tensor-parallel-size
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TensorParallelSize *int32 `json:"tensorParallelSize,omitempty"`
+	// The context length of the model, --max-model-len
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxModelLen *int32 `json:"maxModelLen,omitempty"`
+	// The fraction of each GPU's memory vLLM may use, e.g. 0.9, --gpu-memory-utilization
+	// +kubebuilder:validation:Pattern=`^(0?\.[0-9]*[1-9][0-9]*|1(\.0*)?)$`
+	// +optional
+	GPUMemoryUtilization string `json:"gpuMemoryUtilization,omitempty"`
+	// The maximum number of sequences in a batch, --max-num-seqs
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxNumSeqs *int32 `json:"maxNumSeqs,omitempty"`
+	// Reuse the KV cache of shared prompt prefixes, --enable-prefix-caching
+	// +optional
+	EnablePrefixCaching *bool `json:"enablePrefixCaching,omitempty"`
+	// The model name used by the OpenAI API, --served-model-name
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ServedModelName string `json:"servedModelName,omitempty"`
+	// The highest rank of the model's LoRA adapters, --max-lora-rank
+	// +kubebuilder:validation:Enum=8;16;32;64;128;256
+	// +optional
+	MaxLoRARank *int32 `json:"maxLoraRank,omitempty"`
+	// A smaller model which proposes tokens for the model to check, so that
+	// several tokens can be generated in one step, --speculative-model
+	// +optional
+	DraftModel *AIModel `json:"draftModel,omitempty"`
+	// The number of tokens the draft model proposes at a time, 5 by default,
+	// --num-speculative-tokens
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumSpeculativeTokens *int32 `json:"numSpeculativeTokens,omitempty"`
+	// The number of GPUs the draft model is split across, it must be 1 or the
+	// same as the model's, --speculative-draft-tensor-parallel-size
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DraftTensorParallelSize *int32 `json:"draftTensorParallelSize,omitempty"`
+}
+
+type DeepSpeedMiiOptions struct {
+	// The data type of the weights, --dtype
+	// +kubebuilder:validation:Enum=float16;bfloat16
+	// +optional
+	Dtype string `json:"dtype,omitempty"`
+	// The quantization applied when the model is loaded, --quantization
+	// +kubebuilder:validation:Enum=wf6af16
+	// +optional
+	Quantization string `json:"quantization,omitempty"`
+	// The number of GPUs the model is split across, --tensor-parallel
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TensorParallelSize *int32 `json:"tensorParallelSize,omitempty"`
+	// The most tokens, prompt and generated, a request can have, --max-tokens
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxTokens *int32 `json:"maxTokens,omitempty"`
+}
+
+type TGIOptions struct {
+	// The data type of the weights, it can't be used with quantization, --dtype
+	// +kubebuilder:validation:Enum=float16;bfloat16
+	// +optional
+	Dtype string `json:"dtype,omitempty"`
+	// The quantization method of the weights, --quantize
+	// +kubebuilder:validation:Enum=awq;eetq;exl2;gptq;marlin;bitsandbytes;bitsandbytes-nf4;bitsandbytes-fp4;fp8
+	// +optional
+	Quantization string `json:"quantization,omitempty"`
+	// The number of GPUs the model is sharded across, --num-shard
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TensorParallelSize *int32 `json:"tensorParallelSize,omitempty"`
+}
+
+type SGLangOptions struct {
+	// The data type of the weights and activations, --dtype
+	// +kubebuilder:validation:Enum=auto;half;float16;bfloat16;float;float32
+	// +optional
+	Dtype string `json:"dtype,omitempty"`
+	// The quantization method the weights were prepared with, --quantization
+	// +kubebuilder:validation:Enum=awq;awq_marlin;fp8;gptq;gptq_marlin;marlin;bitsandbytes;gguf
+	// +optional
+	Quantization string `json:"quantization,omitempty"`
+	// The number of GPUs the model is split across, --tp-size
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TensorParallelSize *int32 `json:"tensorParallelSize,omitempty"`
+}
+
+type LlamaCppOptions struct {
+	// The context length, 0 uses the model's, --ctx-size
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CtxSize *int32 `json:"ctxSize,omitempty"`
+	// The number of threads, by default the CPUs the engine is given, --threads
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Threads *int32 `json:"threads,omitempty"`
+	// The number of layers offloaded to the GPU, by default all of them when
+	// there is a GPU, --n-gpu-layers
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GPULayers *int32 `json:"gpuLayers,omitempty"`
+}
+
+type TEIOptions struct {
+	// The data type of the weights, --dtype
+	// +kubebuilder:validation:Enum=float16;float32
+	// +optional
+	Dtype string `json:"dtype,omitempty"`
+}
+
+type WhisperOptions struct {
+	// The number of threads, by default the CPUs the engine is given, --threads
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Threads *int32 `json:"threads,omitempty"`
+}
+
+type EngineImage struct {
+	// The image's repository, e.g. vllm/vllm-openai
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*$`
+	// +optional
+	Repository string `json:"repository,omitempty"`
+	// The image's tag
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	// +optional
+	Tag string `json:"tag,omitempty"`
 }
 
 type AIModel struct {
@@ -167,6 +424,26 @@ type AIDeploymentStatus struct {
 	// Error message if the deployment failed, otherwise it is empty
 	// +optional
 	ErrMsg string `json:"errMsg,omitempty"`
+	// The images used by the Deployment with their tags resolved to digests
+	// +optional
+	Images []ResolvedImage `json:"images,omitempty"`
+	// The generation of the spec the images were resolved for
+	// +optional
+	ImagesObservedGeneration int64 `json:"imagesObservedGeneration,omitempty"`
+	// The URL of the InferenceService when the workload is kserve
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Problems with the spec which don't stop it being deployed, such as
+	// engine options the engine doesn't know
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ResolvedImage struct {
+	// The image as it was rendered, e.g. vllm/vllm-openai:latest
+	Image string `json:"image"`
+	// The image pinned to a digest, e.g. vllm/vllm-openai:latest@sha256:...
+	Reference string `json:"reference"`
 }
 
 //+kubebuilder:object:root=true
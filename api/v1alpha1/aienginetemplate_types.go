@@ -0,0 +1,122 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum=None;HTTP
+type ModelDownloadStrategy string
+
+const (
+	// The engine fetches the models itself, e.g. from Hugging Face
+	ModelDownloadStrategyNone ModelDownloadStrategy = "None"
+	// Models with an http(s) URI are downloaded into the models volume by
+	// an init container before the engine starts
+	ModelDownloadStrategyHTTP ModelDownloadStrategy = "HTTP"
+)
+
+type ModelDownload struct {
+	// Defaults to None
+	// +optional
+	Strategy ModelDownloadStrategy `json:"strategy,omitempty"`
+	// Where the models volume is mounted in the engine, defaults to /models
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// The image of the download init container, it must contain curl.
+	// Defaults to the engine's image.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// AIEngineTemplateSpec describes an engine's container. The command, args
+// and env values are Go templates which are rendered with the AIDeployment's
+// models, for example "--model={{ .Model.Spec.Uri }}". Args which render to
+// an empty string are dropped.
+type AIEngineTemplateSpec struct {
+	Image string `json:"image"`
+	// +optional
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// The port the engine serves its API on
+	// +kubebuilder:default=8000
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// +optional
+	Env []v1.EnvVar `json:"env,omitempty"`
+
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// The probes are merged with the probe settings in the AIDeployment
+	// +optional
+	StartupProbe *v1.Probe `json:"startupProbe,omitempty"`
+	// +optional
+	ReadinessProbe *v1.Probe `json:"readinessProbe,omitempty"`
+	// +optional
+	LivenessProbe *v1.Probe `json:"livenessProbe,omitempty"`
+
+	// How the models are made available to the engine
+	// +optional
+	ModelDownload ModelDownload `json:"modelDownload,omitempty"`
+}
+
+type AIEngineTemplateReference struct {
+	// Must be empty or the namespace of the AIDeployment, an AIDeployment
+	// can only use the templates in its own namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// AIEngineTemplateStatus defines the observed state of AIEngineTemplate
+type AIEngineTemplateStatus struct {
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// AIEngineTemplate is the Schema for the aienginetemplates API
+type AIEngineTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AIEngineTemplateSpec   `json:"spec,omitempty"`
+	Status AIEngineTemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AIEngineTemplateList contains a list of AIEngineTemplate
+type AIEngineTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AIEngineTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AIEngineTemplate{}, &AIEngineTemplateList{})
+}
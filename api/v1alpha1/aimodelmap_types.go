@@ -53,6 +53,65 @@ type AIModelSpec struct {
 	// Config file particular to the engine e.g. a LocalAI model specification
 	// +optional
 	EngineConfigFile string `json:"engineConfigFile,omitempty"`
+
+	// Set when the model is a TensorRT-LLM engine to be served by Triton
+	// +optional
+	TensorRTLLM *TensorRTLLM `json:"tensorRTLLM,omitempty"`
+
+	// The model name clients use in the OpenAI API instead of the URI
+	// +optional
+	ServedName string `json:"servedName,omitempty"`
+	// Other names the model can be called by
+	// +optional
+	Aliases []string `json:"aliases,omitempty"`
+	// A Jinja chat template for models which don't have one or whose template
+	// needs replacing. It is mounted from the AIModelMap's ConfigMap, so it
+	// can't be set on an inline model.
+	// +optional
+	ChatTemplate string `json:"chatTemplate,omitempty"`
+
+	// The tokenizer the model was trained with, e.g. llama3. A draft model must
+	// have the same tokenizer as the model it drafts for.
+	// +optional
+	TokenizerFamily string `json:"tokenizerFamily,omitempty"`
+
+	// LoRA adapters served on top of the model, each under its own model name
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Adapters []LoRAAdapter `json:"adapters,omitempty"`
+}
+
+// A LoRA adapter fine-tuned from the model it is listed in
+type LoRAAdapter struct {
+	// The model name clients use to select the adapter
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=50
+	Name string `json:"name"`
+	// Where the adapter is downloaded from. vLLM takes a Hugging Face repository
+	// or an http(s) URL of a .tar.gz of the adapter's directory, LocalAI takes an
+	// http(s) URL of a GGUF file.
+	// +kubebuilder:validation:MinLength=1
+	Uri string `json:"uri"`
+}
+
+// TensorRTLLM describes a model compiled with TensorRT-LLM. The model's URI is
+// a tarball holding the compiled engine and the model's tokenizer.
+type TensorRTLLM struct {
+	// Directory of the compiled engine in the tarball
+	// +kubebuilder:default=engine
+	// +optional
+	EngineDir string `json:"engineDir,omitempty"`
+	// Directory of the Hugging Face tokenizer in the tarball
+	// +kubebuilder:default=tokenizer
+	// +optional
+	TokenizerDir string `json:"tokenizerDir,omitempty"`
+	// Largest batch Triton sends to the engine, it can't be more than the
+	// engine was built with
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=8
+	// +optional
+	MaxBatchSize int32 `json:"maxBatchSize,omitempty"`
 }
 
 type AIModelMapReference struct {
@@ -74,6 +133,20 @@ type AIModelMapSpec struct {
 	Vllm         []AIModelVariant `json:"vllm,omitempty"`
 	DeepSpeedMii []AIModelVariant `json:"deepspeed-mii,omitempty"`
 	TensorRT     []AIModelVariant `json:"tensor_rt,omitempty"`
+	TGI          []AIModelVariant `json:"tgi,omitempty"`
+	Ollama       []AIModelVariant `json:"ollama,omitempty"`
+	LlamaCpp     []AIModelVariant `json:"llamacpp,omitempty"`
+	// SGLang uses the vllm variants when it has none of its own
+	// +optional
+	SGLang []AIModelVariant `json:"sglang,omitempty"`
+	// Embedding and reranker models, served by the tei engine
+	// +optional
+	Embeddings []AIModelVariant `json:"embeddings,omitempty"`
+	// +optional
+	Whisper []AIModelVariant `json:"whisper,omitempty"`
+	// Variants for engines described by AIEngineTemplates, by template name
+	// +optional
+	Templates map[string][]AIModelVariant `json:"templates,omitempty"`
 }
 
 // AIModelMapStatus defines the observed state of AIModelMap
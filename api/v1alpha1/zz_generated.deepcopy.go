@@ -32,7 +32,7 @@ func (in *AIDeployment) DeepCopyInto(out *AIDeployment) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIDeployment.
@@ -116,6 +116,11 @@ func (in *AIDeploymentSpec) DeepCopyInto(out *AIDeploymentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Distributed != nil {
+		in, out := &in.Distributed, &out.Distributed
+		*out = new(Distributed)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIDeploymentSpec.
@@ -131,6 +136,16 @@ func (in *AIDeploymentSpec) DeepCopy() *AIDeploymentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIDeploymentStatus) DeepCopyInto(out *AIDeploymentStatus) {
 	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ResolvedImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIDeploymentStatus.
@@ -153,6 +168,51 @@ func (in *AIEngine) DeepCopyInto(out *AIEngine) {
 			(*out)[key] = val
 		}
 	}
+	if in.VLLM != nil {
+		in, out := &in.VLLM, &out.VLLM
+		*out = new(VLLMOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeepSpeedMii != nil {
+		in, out := &in.DeepSpeedMii, &out.DeepSpeedMii
+		*out = new(DeepSpeedMiiOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TGI != nil {
+		in, out := &in.TGI, &out.TGI
+		*out = new(TGIOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SGLang != nil {
+		in, out := &in.SGLang, &out.SGLang
+		*out = new(SGLangOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LlamaCpp != nil {
+		in, out := &in.LlamaCpp, &out.LlamaCpp
+		*out = new(LlamaCppOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TEI != nil {
+		in, out := &in.TEI, &out.TEI
+		*out = new(TEIOptions)
+		**out = **in
+	}
+	if in.Whisper != nil {
+		in, out := &in.Whisper, &out.Whisper
+		*out = new(WhisperOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(EngineImage)
+		**out = **in
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(AIEngineTemplateReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIEngine.
@@ -165,6 +225,149 @@ func (in *AIEngine) DeepCopy() *AIEngine {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIEngineTemplate) DeepCopyInto(out *AIEngineTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIEngineTemplate.
+func (in *AIEngineTemplate) DeepCopy() *AIEngineTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(AIEngineTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AIEngineTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIEngineTemplateList) DeepCopyInto(out *AIEngineTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AIEngineTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIEngineTemplateList.
+func (in *AIEngineTemplateList) DeepCopy() *AIEngineTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(AIEngineTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AIEngineTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIEngineTemplateReference) DeepCopyInto(out *AIEngineTemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIEngineTemplateReference.
+func (in *AIEngineTemplateReference) DeepCopy() *AIEngineTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AIEngineTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIEngineTemplateSpec) DeepCopyInto(out *AIEngineTemplateSpec) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	out.ModelDownload = in.ModelDownload
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIEngineTemplateSpec.
+func (in *AIEngineTemplateSpec) DeepCopy() *AIEngineTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AIEngineTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AIEngineTemplateStatus) DeepCopyInto(out *AIEngineTemplateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIEngineTemplateStatus.
+func (in *AIEngineTemplateStatus) DeepCopy() *AIEngineTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AIEngineTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIModel) DeepCopyInto(out *AIModel) {
 	*out = *in
@@ -173,7 +376,7 @@ func (in *AIModel) DeepCopyInto(out *AIModel) {
 		*out = new(AIModelMapReference)
 		**out = **in
 	}
-	out.AIModelSpec = in.AIModelSpec
+	in.AIModelSpec.DeepCopyInto(&out.AIModelSpec)
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make(map[string]string, len(*in))
@@ -273,22 +476,90 @@ func (in *AIModelMapSpec) DeepCopyInto(out *AIModelMapSpec) {
 	if in.Localai != nil {
 		in, out := &in.Localai, &out.Localai
 		*out = make([]AIModelVariant, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Vllm != nil {
 		in, out := &in.Vllm, &out.Vllm
 		*out = make([]AIModelVariant, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.DeepSpeedMii != nil {
 		in, out := &in.DeepSpeedMii, &out.DeepSpeedMii
 		*out = make([]AIModelVariant, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.TensorRT != nil {
 		in, out := &in.TensorRT, &out.TensorRT
 		*out = make([]AIModelVariant, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TGI != nil {
+		in, out := &in.TGI, &out.TGI
+		*out = make([]AIModelVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ollama != nil {
+		in, out := &in.Ollama, &out.Ollama
+		*out = make([]AIModelVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LlamaCpp != nil {
+		in, out := &in.LlamaCpp, &out.LlamaCpp
+		*out = make([]AIModelVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SGLang != nil {
+		in, out := &in.SGLang, &out.SGLang
+		*out = make([]AIModelVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Embeddings != nil {
+		in, out := &in.Embeddings, &out.Embeddings
+		*out = make([]AIModelVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Whisper != nil {
+		in, out := &in.Whisper, &out.Whisper
+		*out = make([]AIModelVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string][]AIModelVariant, len(*in))
+		for key, val := range *in {
+			var outVal []AIModelVariant
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]AIModelVariant, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
 	}
 }
 
@@ -320,6 +591,21 @@ func (in *AIModelMapStatus) DeepCopy() *AIModelMapStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIModelSpec) DeepCopyInto(out *AIModelSpec) {
 	*out = *in
+	if in.TensorRTLLM != nil {
+		in, out := &in.TensorRTLLM, &out.TensorRTLLM
+		*out = new(TensorRTLLM)
+		**out = **in
+	}
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Adapters != nil {
+		in, out := &in.Adapters, &out.Adapters
+		*out = make([]LoRAAdapter, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIModelSpec.
@@ -335,7 +621,7 @@ func (in *AIModelSpec) DeepCopy() *AIModelSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AIModelVariant) DeepCopyInto(out *AIModelVariant) {
 	*out = *in
-	out.AIModelSpec = in.AIModelSpec
+	in.AIModelSpec.DeepCopyInto(&out.AIModelSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AIModelVariant.
@@ -465,6 +751,31 @@ func (in *AutoNodeLabelerStatus) DeepCopy() *AutoNodeLabelerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeepSpeedMiiOptions) DeepCopyInto(out *DeepSpeedMiiOptions) {
+	*out = *in
+	if in.TensorParallelSize != nil {
+		in, out := &in.TensorParallelSize, &out.TensorParallelSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTokens != nil {
+		in, out := &in.MaxTokens, &out.MaxTokens
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeepSpeedMiiOptions.
+func (in *DeepSpeedMiiOptions) DeepCopy() *DeepSpeedMiiOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DeepSpeedMiiOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Deployment) DeepCopyInto(out *Deployment) {
 	*out = *in
@@ -482,6 +793,11 @@ func (in *Deployment) DeepCopyInto(out *Deployment) {
 			(*out)[key] = val
 		}
 	}
+	if in.VolumeClaimTemplate != nil {
+		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
+		*out = new(v1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Accelerator != nil {
 		in, out := &in.Accelerator, &out.Accelerator
 		*out = new(Accelerator)
@@ -505,6 +821,11 @@ func (in *Deployment) DeepCopyInto(out *Deployment) {
 		*out = new(v1.PodTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.StartupProbe != nil {
 		in, out := &in.StartupProbe, &out.StartupProbe
 		*out = new(Probe)
@@ -532,6 +853,21 @@ func (in *Deployment) DeepCopy() *Deployment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Distributed) DeepCopyInto(out *Distributed) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Distributed.
+func (in *Distributed) DeepCopy() *Distributed {
+	if in == nil {
+		return nil
+	}
+	out := new(Distributed)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Endpoint) DeepCopyInto(out *Endpoint) {
 	*out = *in
@@ -547,6 +883,21 @@ func (in *Endpoint) DeepCopy() *Endpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineImage) DeepCopyInto(out *EngineImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineImage.
+func (in *EngineImage) DeepCopy() *EngineImage {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ingress) DeepCopyInto(out *Ingress) {
 	*out = *in
@@ -611,6 +962,66 @@ func (in *LabelSelectorRequirementApplyConfiguration) DeepCopy() *LabelSelectorR
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LlamaCppOptions) DeepCopyInto(out *LlamaCppOptions) {
+	*out = *in
+	if in.CtxSize != nil {
+		in, out := &in.CtxSize, &out.CtxSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.GPULayers != nil {
+		in, out := &in.GPULayers, &out.GPULayers
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LlamaCppOptions.
+func (in *LlamaCppOptions) DeepCopy() *LlamaCppOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(LlamaCppOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoRAAdapter) DeepCopyInto(out *LoRAAdapter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoRAAdapter.
+func (in *LoRAAdapter) DeepCopy() *LoRAAdapter {
+	if in == nil {
+		return nil
+	}
+	out := new(LoRAAdapter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelDownload) DeepCopyInto(out *ModelDownload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelDownload.
+func (in *ModelDownload) DeepCopy() *ModelDownload {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelDownload)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Probe) DeepCopyInto(out *Probe) {
 	*out = *in
@@ -636,6 +1047,41 @@ func (in *Probe) DeepCopy() *Probe {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedImage) DeepCopyInto(out *ResolvedImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedImage.
+func (in *ResolvedImage) DeepCopy() *ResolvedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SGLangOptions) DeepCopyInto(out *SGLangOptions) {
+	*out = *in
+	if in.TensorParallelSize != nil {
+		in, out := &in.TensorParallelSize, &out.TensorParallelSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SGLangOptions.
+func (in *SGLangOptions) DeepCopy() *SGLangOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SGLangOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -665,6 +1111,111 @@ func (in *Service) DeepCopy() *Service {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TEIOptions) DeepCopyInto(out *TEIOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TEIOptions.
+func (in *TEIOptions) DeepCopy() *TEIOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TEIOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TGIOptions) DeepCopyInto(out *TGIOptions) {
+	*out = *in
+	if in.TensorParallelSize != nil {
+		in, out := &in.TensorParallelSize, &out.TensorParallelSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TGIOptions.
+func (in *TGIOptions) DeepCopy() *TGIOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TGIOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TensorRTLLM) DeepCopyInto(out *TensorRTLLM) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TensorRTLLM.
+func (in *TensorRTLLM) DeepCopy() *TensorRTLLM {
+	if in == nil {
+		return nil
+	}
+	out := new(TensorRTLLM)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLLMOptions) DeepCopyInto(out *VLLMOptions) {
+	*out = *in
+	if in.TensorParallelSize != nil {
+		in, out := &in.TensorParallelSize, &out.TensorParallelSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxModelLen != nil {
+		in, out := &in.MaxModelLen, &out.MaxModelLen
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxNumSeqs != nil {
+		in, out := &in.MaxNumSeqs, &out.MaxNumSeqs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EnablePrefixCaching != nil {
+		in, out := &in.EnablePrefixCaching, &out.EnablePrefixCaching
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxLoRARank != nil {
+		in, out := &in.MaxLoRARank, &out.MaxLoRARank
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DraftModel != nil {
+		in, out := &in.DraftModel, &out.DraftModel
+		*out = new(AIModel)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NumSpeculativeTokens != nil {
+		in, out := &in.NumSpeculativeTokens, &out.NumSpeculativeTokens
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DraftTensorParallelSize != nil {
+		in, out := &in.DraftTensorParallelSize, &out.DraftTensorParallelSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLLMOptions.
+func (in *VLLMOptions) DeepCopy() *VLLMOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VLLMOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Version) DeepCopyInto(out *Version) {
 	*out = *in
@@ -679,3 +1230,23 @@ func (in *Version) DeepCopy() *Version {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WhisperOptions) DeepCopyInto(out *WhisperOptions) {
+	*out = *in
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhisperOptions.
+func (in *WhisperOptions) DeepCopy() *WhisperOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(WhisperOptions)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -4,14 +4,33 @@ import argparse
 
 parser = argparse.ArgumentParser()
 parser.add_argument('--uri', required=True, help='Model URI e.g. microsoft/phi-1_5')
+parser.add_argument('--tensor-parallel', type=int, default=1, help='Number of GPUs to split the model across')
+parser.add_argument('--deployment-name', default='default', help='Name of the deployment, the API is served on /mii/<name>')
+parser.add_argument('--port', type=int, default=8080, help='Port of the REST API')
+parser.add_argument('--grpc-port', type=int, default=50050, help='First port used by the gRPC servers')
+parser.add_argument('--dtype', choices=['float16', 'bfloat16'], help='Data type of the weights')
+parser.add_argument('--quantization', choices=['wf6af16'], help='Quantization applied when the model is loaded')
+parser.add_argument('--max-tokens', type=int, help='Most tokens, prompt and generated, in a request')
 
 args = parser.parse_args()
 
+# MII's defaults are used for the options which aren't given
+options = {}
+if args.dtype:
+    options['dtype'] = args.dtype
+if args.quantization:
+    options['quantization_mode'] = args.quantization
+if args.max_tokens:
+    options['max_length'] = args.max_tokens
+
 client = mii.serve(args.uri,
-                   deployment_name="default",
+                   deployment_name=args.deployment_name,
+                   tensor_parallel=args.tensor_parallel,
+                   port_number=args.grpc_port,
                    enable_restful_api=True,
-                   restful_api_port=8080,
-                   restful_api_host="0.0.0.0")
+                   restful_api_port=args.port,
+                   restful_api_host="0.0.0.0",
+                   **options)
 
 while True:
     time.sleep(1000)
@@ -0,0 +1,13 @@
+package aideployment
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAIDeployment(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AIDeployment Suite")
+}
@@ -0,0 +1,117 @@
+package aideployment
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+)
+
+// DistributedEngine is an MLEngine which can split a model across nodes. The
+// engine's Deployment is the leader and the workers join it through the head
+// Service.
+type DistributedEngine interface {
+	MLEngine
+	// Workers returns the StatefulSet of the nodes other than the leader
+	Workers(owner metav1.Object) (*appsv1.StatefulSet, error)
+	// HeadPorts are the ports of the leader the workers connect to
+	HeadPorts() []v1.ServicePort
+}
+
+// applyPinnedImages uses the digests pinImages resolved for the leader
+func applyPinnedImages(sd *v1alpha1.AIDeployment, pod *v1.PodSpec) {
+	pinned := map[string]string{}
+	for _, i := range sd.Status.Images {
+		pinned[i.Image] = i.Reference
+	}
+
+	for i := range pod.InitContainers {
+		if ref, ok := pinned[pod.InitContainers[i].Image]; ok {
+			pod.InitContainers[i].Image = ref
+		}
+	}
+	for i := range pod.Containers {
+		if ref, ok := pinned[pod.Containers[i].Image]; ok {
+			pod.Containers[i].Image = ref
+		}
+	}
+}
+
+// reconcileWorkers creates or updates the workers and the head Service of a
+// distributed AIDeployment, or removes them when it isn't distributed
+func reconcileWorkers(ctx context.Context, c ctrlClient.Client, sd *v1alpha1.AIDeployment, mle MLEngine) error {
+	lg := log.FromContext(ctx)
+	workersName := resources.WorkersName(sd.Name)
+	headName := resources.HeadServiceName(sd.Name)
+
+	if sd.Spec.Distributed == nil {
+		if err := deleteIfExists(ctx, c, sd, sd.Namespace, workersName, &appsv1.StatefulSet{}); err != nil {
+			return err
+		}
+		return deleteIfExists(ctx, c, sd, sd.Namespace, headName, &v1.Service{})
+	}
+
+	dmle, ok := mle.(DistributedEngine)
+	if !ok {
+		return fmt.Errorf("the %s engine can't be distributed", sd.Spec.Engine.Name)
+	}
+	if sd.Spec.Workload == v1alpha1.WorkloadKServe {
+		return fmt.Errorf("a distributed deployment can't use the kserve workload")
+	}
+
+	workers, err := dmle.Workers(&sd.ObjectMeta)
+	if err != nil {
+		return err
+	}
+
+	if err := addPodSchedulingProperties(&workers.Spec.Template, sd.Spec); err != nil {
+		return err
+	}
+	// The workers load the model as well, so they keep it in the same way
+	if sd.Spec.Deployment.Kind == v1alpha1.DeploymentKindStatefulSet {
+		if err := persistModels(sd, workers); err != nil {
+			return err
+		}
+	}
+	mirrorImages(&workers.Spec.Template.Spec)
+	applyPinnedImages(sd, &workers.Spec.Template.Spec)
+
+	lg.V(1).Info("Applying workers", "namespace", workers.Namespace, "name", workers.Name)
+	conflict, err := applyStatefulSet(ctx, c, workers)
+	if err != nil {
+		return err
+	}
+	if conflict {
+		lg.Info("Workers changed during update, they are updated on the next reconcile")
+	}
+
+	head := resources.DesiredHeadlessService(
+		&sd.ObjectMeta,
+		headName,
+		sd.Namespace,
+		resources.GenDefaultLabels(sd.Name),
+		dmle.HeadPorts(),
+	)
+
+	current := &v1.Service{}
+	if err := c.Get(ctx, ctrlClient.ObjectKeyFromObject(head), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		lg.V(1).Info("Creating head service", "namespace", head.Namespace, "name", head.Name)
+		return c.Create(ctx, head)
+	}
+
+	head.ResourceVersion = current.ResourceVersion
+	lg.V(1).Info("Updating head service", "namespace", head.Namespace, "name", head.Name)
+	return c.Update(ctx, head)
+}
@@ -0,0 +1,157 @@
+package aideployment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/config"
+	"github.com/premAI-io/prem-operator/pkg/registry"
+)
+
+// mirrorImages points every container in the pod, including the init
+// containers, at the registry mirrors in the operator config
+func mirrorImages(pod *v1.PodSpec) {
+	cfg := config.Get()
+
+	for i := range pod.InitContainers {
+		pod.InitContainers[i].Image = cfg.MirrorImage(pod.InitContainers[i].Image)
+	}
+	for i := range pod.Containers {
+		pod.Containers[i].Image = cfg.MirrorImage(pod.Containers[i].Image)
+	}
+}
+
+// pinImages adds the digest each image's tag resolves to and records them in
+// the AIDeployment's status. Unless the policy is Always, the digests in the
+// status are reused while the spec's generation is unchanged. Images which
+// can't be resolved keep their tag and are listed in the status warnings,
+// the resolver decides when they are tried again. The registries are given
+// the credentials in the pod's pull secrets, which are read with secrets.
+func pinImages(
+	ctx context.Context,
+	res registry.Resolver,
+	secrets ctrlClient.Reader,
+	sd *v1alpha1.AIDeployment,
+	pod *v1.PodSpec,
+) {
+	if res == nil || sd.Spec.ImageUpdatePolicy == v1alpha1.ImageUpdatePolicyNever {
+		sd.Status.Images = nil
+		sd.Status.ImagesObservedGeneration = 0
+		return
+	}
+
+	lg := log.FromContext(ctx)
+
+	known := map[string]string{}
+	if sd.Spec.ImageUpdatePolicy != v1alpha1.ImageUpdatePolicyAlways &&
+		sd.Status.ImagesObservedGeneration == sd.Generation {
+		for _, i := range sd.Status.Images {
+			known[i.Image] = i.Reference
+		}
+	}
+
+	images := []v1alpha1.ResolvedImage{}
+	pinned := map[string]string{}
+
+	// The secrets are only read if an image has to be resolved
+	var keys registry.Keychain
+	keychain := func() registry.Keychain {
+		if keys == nil {
+			keys = pullCredentials(ctx, secrets, sd, pod.ImagePullSecrets)
+		}
+		return keys
+	}
+
+	pin := func(c *v1.Container) {
+		if c.Image == "" || strings.Contains(c.Image, "@") {
+			return
+		}
+
+		ref, ok := pinned[c.Image]
+		if !ok {
+			if ref, ok = known[c.Image]; !ok {
+				digest, err := res.Resolve(ctx, c.Image, keychain())
+				if err != nil {
+					lg.V(1).Info("Failed to resolve image digest, using the tag", "image", c.Image, "error", err.Error())
+					sd.Status.Warnings = append(sd.Status.Warnings, fmt.Sprintf(
+						"image %s isn't pinned to a digest: %v", c.Image, err,
+					))
+					// Each container with the image would fail in the same way
+					pinned[c.Image] = c.Image
+					return
+				}
+				ref = c.Image + "@" + digest
+			}
+
+			pinned[c.Image] = ref
+			images = append(images, v1alpha1.ResolvedImage{Image: c.Image, Reference: ref})
+		}
+
+		c.Image = ref
+	}
+
+	for i := range pod.InitContainers {
+		pin(&pod.InitContainers[i])
+	}
+	for i := range pod.Containers {
+		pin(&pod.Containers[i])
+	}
+
+	sd.Status.Images = images
+	sd.Status.ImagesObservedGeneration = sd.Generation
+}
+
+// pullCredentials reads the registry credentials from the pull secrets. A
+// secret which can't be used is listed in the status warnings, as the kubelet
+// won't be able to use it either.
+func pullCredentials(
+	ctx context.Context,
+	secrets ctrlClient.Reader,
+	sd *v1alpha1.AIDeployment,
+	refs []v1.LocalObjectReference,
+) registry.Keychain {
+	keys := registry.Keychain{}
+	if secrets == nil {
+		return keys
+	}
+
+	for _, ref := range refs {
+		secret := &v1.Secret{}
+		err := secrets.Get(ctx, ctrlClient.ObjectKey{Namespace: sd.Namespace, Name: ref.Name}, secret)
+
+		var data []byte
+		if err == nil {
+			switch secret.Type {
+			case v1.SecretTypeDockerConfigJson:
+				data = secret.Data[v1.DockerConfigJsonKey]
+			case v1.SecretTypeDockercfg:
+				data = secret.Data[v1.DockerConfigKey]
+			default:
+				err = fmt.Errorf("its type %s isn't %s", secret.Type, v1.SecretTypeDockerConfigJson)
+			}
+		}
+
+		var found registry.Keychain
+		if err == nil {
+			found, err = registry.ParseDockerConfig(data)
+		}
+		if err != nil {
+			sd.Status.Warnings = append(sd.Status.Warnings, fmt.Sprintf(
+				"image pull secret %s can't be used to pin images: %v", ref.Name, err,
+			))
+			continue
+		}
+
+		// As with the kubelet, the first secret with a registry's
+		// credentials is used
+		keys = keys.Merge(found)
+	}
+
+	return keys
+}
@@ -0,0 +1,144 @@
+package aideployment
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/pkg/registry"
+)
+
+// fakeResolver returns a digest made from the number of times it was called
+// and keeps the last keychain it was given
+type fakeResolver struct {
+	calls int
+	fail  map[string]bool
+	keys  registry.Keychain
+}
+
+func (f *fakeResolver) Resolve(_ context.Context, image string, keys registry.Keychain) (string, error) {
+	f.keys = keys
+	if f.fail[image] {
+		return "", fmt.Errorf("unreachable")
+	}
+
+	f.calls++
+	return fmt.Sprintf("sha256:%d", f.calls), nil
+}
+
+var _ = Describe("pinImages", func() {
+	var (
+		res *fakeResolver
+		sd  *v1alpha1.AIDeployment
+		pod func() *v1.PodSpec
+	)
+
+	BeforeEach(func() {
+		res = &fakeResolver{fail: map[string]bool{}}
+		sd = &v1alpha1.AIDeployment{}
+		sd.Generation = 1
+		pod = func() *v1.PodSpec {
+			return &v1.PodSpec{
+				InitContainers: []v1.Container{{Image: "engine:latest"}},
+				Containers: []v1.Container{
+					{Image: "engine:latest"},
+					{Image: "sidecar@sha256:abc"},
+				},
+			}
+		}
+	})
+
+	It("resolves each image once and records it in the status", func(ctx SpecContext) {
+		p := pod()
+		pinImages(ctx, res, nil, sd, p)
+
+		Expect(res.calls).To(Equal(1))
+		Expect(p.InitContainers[0].Image).To(Equal("engine:latest@sha256:1"))
+		Expect(p.Containers[0].Image).To(Equal("engine:latest@sha256:1"))
+		Expect(p.Containers[1].Image).To(Equal("sidecar@sha256:abc"))
+		Expect(sd.Status.Images).To(ConsistOf(v1alpha1.ResolvedImage{
+			Image: "engine:latest", Reference: "engine:latest@sha256:1",
+		}))
+		Expect(sd.Status.ImagesObservedGeneration).To(Equal(int64(1)))
+	})
+
+	It("reuses the status until the generation changes", func(ctx SpecContext) {
+		pinImages(ctx, res, nil, sd, pod())
+
+		p := pod()
+		pinImages(ctx, res, nil, sd, p)
+		Expect(res.calls).To(Equal(1))
+		Expect(p.Containers[0].Image).To(Equal("engine:latest@sha256:1"))
+
+		sd.Generation = 2
+		p = pod()
+		pinImages(ctx, res, nil, sd, p)
+		Expect(res.calls).To(Equal(2))
+		Expect(p.Containers[0].Image).To(Equal("engine:latest@sha256:2"))
+	})
+
+	It("resolves on every call with the Always policy", func(ctx SpecContext) {
+		sd.Spec.ImageUpdatePolicy = v1alpha1.ImageUpdatePolicyAlways
+		pinImages(ctx, res, nil, sd, pod())
+		pinImages(ctx, res, nil, sd, pod())
+
+		Expect(res.calls).To(Equal(2))
+	})
+
+	It("leaves the tags with the Never policy", func(ctx SpecContext) {
+		sd.Spec.ImageUpdatePolicy = v1alpha1.ImageUpdatePolicyNever
+		p := pod()
+		pinImages(ctx, res, nil, sd, p)
+
+		Expect(res.calls).To(BeZero())
+		Expect(p.Containers[0].Image).To(Equal("engine:latest"))
+		Expect(sd.Status.Images).To(BeNil())
+	})
+
+	It("keeps the tag of an image which can't be resolved", func(ctx SpecContext) {
+		res.fail["engine:latest"] = true
+		p := pod()
+		pinImages(ctx, res, nil, sd, p)
+
+		Expect(p.Containers[0].Image).To(Equal("engine:latest"))
+		Expect(sd.Status.Images).To(BeEmpty())
+		Expect(sd.Status.Warnings).To(ConsistOf("image engine:latest isn't pinned to a digest: unreachable"))
+	})
+
+	It("gives the registries the credentials in the pull secrets", func(ctx SpecContext) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		secrets := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "nvcr", Namespace: "default"},
+				Type:       v1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					v1.DockerConfigJsonKey: []byte(`{"auths":{"nvcr.io":{"username":"$oauthtoken","password":"key"}}}`),
+				},
+			},
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"},
+				Type:       v1.SecretTypeTLS,
+			},
+		).Build()
+		sd.Namespace = "default"
+		p := pod()
+		p.ImagePullSecrets = []v1.LocalObjectReference{{Name: "nvcr"}, {Name: "tls"}, {Name: "missing"}}
+
+		pinImages(ctx, res, secrets, sd, p)
+
+		Expect(res.keys).To(Equal(registry.Keychain{"nvcr.io": {Username: "$oauthtoken", Password: "key"}}))
+		Expect(sd.Status.Warnings).To(ConsistOf(
+			ContainSubstring("image pull secret tls can't be used"),
+			ContainSubstring("image pull secret missing can't be used"),
+		))
+	})
+})
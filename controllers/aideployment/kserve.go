@@ -0,0 +1,165 @@
+package aideployment
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+// The KServe API is used through unstructured objects so that the operator
+// doesn't depend on KServe and runs on clusters without it
+var inferenceServiceGVK = schema.GroupVersionKind{
+	Group:   "serving.kserve.io",
+	Version: "v1beta1",
+	Kind:    "InferenceService",
+}
+
+// kserveContainerName is the name KServe expects for the predictor's main
+// container
+const kserveContainerName = "kserve-container"
+
+// desiredInferenceService renders the pod of the engine's Deployment as the
+// custom predictor of an InferenceService
+func desiredInferenceService(deployment *appsv1.Deployment, port int32) (*unstructured.Unstructured, error) {
+	pod := deployment.Spec.Template.Spec.DeepCopy()
+	for i := range pod.Containers {
+		c := &pod.Containers[i]
+		if c.Name != constants.ContainerEngineName {
+			continue
+		}
+
+		// Knative only routes to a single port
+		c.Name = kserveContainerName
+		c.Ports = []v1.ContainerPort{{ContainerPort: port, Protocol: v1.ProtocolTCP}}
+	}
+
+	predictor, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the engine pod: %w", err)
+	}
+
+	replicas := int64(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = int64(*deployment.Spec.Replicas)
+	}
+	predictor["minReplicas"] = replicas
+	predictor["maxReplicas"] = replicas
+
+	isvc := &unstructured.Unstructured{}
+	isvc.SetGroupVersionKind(inferenceServiceGVK)
+	isvc.SetName(deployment.Name)
+	isvc.SetNamespace(deployment.Namespace)
+	isvc.SetOwnerReferences(deployment.OwnerReferences)
+	isvc.SetLabels(deployment.Spec.Template.Labels)
+	isvc.SetAnnotations(deployment.Spec.Template.Annotations)
+
+	if err := unstructured.SetNestedField(isvc.Object, predictor, "spec", "predictor"); err != nil {
+		return nil, err
+	}
+
+	return isvc, nil
+}
+
+// inferenceServiceStatus returns the AIDeployment status matching the
+// InferenceService's Ready condition
+func inferenceServiceStatus(isvc *unstructured.Unstructured) (status constants.Status, url string, msg string) {
+	url, _, _ = unstructured.NestedString(isvc.Object, "status", "url")
+	conditions, _, _ := unstructured.NestedSlice(isvc.Object, "status", "conditions")
+
+	status = constants.NotReady
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+
+		if cond["status"] == string(metav1.ConditionTrue) {
+			status = constants.Ready
+		} else if m, ok := cond["message"].(string); ok {
+			msg = m
+		}
+	}
+
+	return status, url, msg
+}
+
+// reconcileInferenceService creates or updates the InferenceService and
+// mirrors its status into the AIDeployment
+func reconcileInferenceService(
+	ctx context.Context,
+	c ctrlClient.Client,
+	sd *v1alpha1.AIDeployment,
+	deployment *appsv1.Deployment,
+	port int32,
+) (int, error) {
+	lg := log.FromContext(ctx)
+
+	isvc, err := desiredInferenceService(deployment, port)
+	if err != nil {
+		return 0, err
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(inferenceServiceGVK)
+	key := types.NamespacedName{Namespace: isvc.GetNamespace(), Name: isvc.GetName()}
+	if err := c.Get(ctx, key, current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return 0, err
+		}
+
+		lg.Info("Creating inference service", "namespace", isvc.GetNamespace(), "name", isvc.GetName())
+		if err := c.Create(ctx, isvc); err != nil {
+			return 0, err
+		}
+	} else {
+		isvc.SetResourceVersion(current.GetResourceVersion())
+
+		lg.V(1).Info("Updating inference service", "namespace", isvc.GetNamespace(), "name", isvc.GetName())
+		if err := c.Update(ctx, isvc); err != nil {
+			if apierrors.IsConflict(err) {
+				lg.Info("Inference service changed during update, requeueing")
+				return 1, nil
+			}
+			return 0, err
+		}
+	}
+
+	// Remove what was created before the workload was switched to kserve
+	for _, o := range []ctrlClient.Object{
+		&appsv1.Deployment{}, &appsv1.StatefulSet{}, &v1.Service{}, &networkv1.Ingress{},
+	} {
+		if err := deleteIfExists(ctx, c, sd, deployment.Namespace, deployment.Name, o); err != nil {
+			return 0, err
+		}
+	}
+
+	aiDep := sd.DeepCopy()
+	status, url, msg := inferenceServiceStatus(isvc)
+	aiDep.Status.Status = status
+	aiDep.Status.URL = url
+	aiDep.Status.ErrMsg = msg
+	if err := c.Status().Update(ctx, aiDep); err != nil {
+		return 0, fmt.Errorf("failed to update AI deployment status: %w", err)
+	}
+
+	// The InferenceService isn't watched as KServe may not be installed
+	if status != constants.Ready {
+		return 3, nil
+	}
+
+	return 0, nil
+}
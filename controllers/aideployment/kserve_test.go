@@ -0,0 +1,110 @@
+package aideployment
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+)
+
+var _ = Describe("InferenceService", func() {
+	It("renders the engine pod as a custom predictor", func() {
+		replicas := int32(2)
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "vllm", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "vllm"}},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{
+							Name:  constants.ContainerEngineName,
+							Image: "vllm/vllm-openai:latest",
+							Args:  []string{"--model", "mistralai/Mistral-7B-v0.1"},
+							Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8000}, {Name: "metrics", ContainerPort: 9090}},
+						}},
+					},
+				},
+			},
+		}
+
+		isvc, err := desiredInferenceService(deployment, 8000)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(isvc.GetAPIVersion()).To(Equal("serving.kserve.io/v1beta1"))
+		Expect(isvc.GetKind()).To(Equal("InferenceService"))
+		Expect(isvc.GetLabels()).To(HaveKeyWithValue("app", "vllm"))
+
+		containers, _, err := unstructured.NestedSlice(isvc.Object, "spec", "predictor", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(containers).To(HaveLen(1))
+		Expect(containers[0]).To(HaveKeyWithValue("name", kserveContainerName))
+		Expect(containers[0]).To(HaveKeyWithValue("ports", ConsistOf(HaveKeyWithValue("containerPort", int64(8000)))))
+
+		minReplicas, _, _ := unstructured.NestedInt64(isvc.Object, "spec", "predictor", "minReplicas")
+		Expect(minReplicas).To(Equal(int64(2)))
+		// The Deployment is left as it was
+		Expect(deployment.Spec.Template.Spec.Containers[0].Name).To(Equal(constants.ContainerEngineName))
+	})
+
+	DescribeTable("mirrors the Ready condition",
+		func(conditions []interface{}, status constants.Status, msg string) {
+			isvc := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"url":        "http://vllm.default.example.com",
+					"conditions": conditions,
+				},
+			}}
+
+			s, url, m := inferenceServiceStatus(isvc)
+			Expect(s).To(Equal(status))
+			Expect(url).To(Equal("http://vllm.default.example.com"))
+			Expect(m).To(Equal(msg))
+		},
+		Entry("ready", []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}, constants.Ready, ""),
+		Entry("not ready", []interface{}{
+			map[string]interface{}{"type": "PredictorReady", "status": "True"},
+			map[string]interface{}{"type": "Ready", "status": "False", "message": "Revision failed"},
+		}, constants.NotReady, "Revision failed"),
+		Entry("no conditions yet", nil, constants.NotReady, ""),
+	)
+	It("only removes the objects the AIDeployment controls", func(ctx SpecContext) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+		controller := true
+		sd := &v1alpha1.AIDeployment{ObjectMeta: metav1.ObjectMeta{Name: "vllm", Namespace: "default", UID: "sd"}}
+		owned := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name: "vllm", Namespace: "default", OwnerReferences: resources.GenOwner(sd),
+		}}
+		// In serverless mode KServe creates a Service with the InferenceService's name
+		kserve := &v1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name: "vllm", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "serving.kserve.io/v1beta1", Kind: "InferenceService", Name: "vllm", UID: "isvc",
+				Controller: &controller,
+			}},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(owned, kserve).Build()
+
+		Expect(deleteIfExists(ctx, c, sd, "default", "vllm", &appsv1.Deployment{})).To(Succeed())
+		Expect(deleteIfExists(ctx, c, sd, "default", "vllm", &v1.Service{})).To(Succeed())
+
+		err := c.Get(ctx, ctrlClient.ObjectKeyFromObject(owned), &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(c.Get(ctx, ctrlClient.ObjectKeyFromObject(kserve), &v1.Service{})).To(Succeed())
+	})
+})
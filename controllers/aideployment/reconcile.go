@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"github.com/premAI-io/prem-operator/controllers/constants"
-	log "github.com/sirupsen/logrus"
+	"github.com/premAI-io/prem-operator/pkg/registry"
+	"github.com/premAI-io/prem-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	networkv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/premAI-io/prem-operator/api/v1alpha1"
 	"github.com/premAI-io/prem-operator/controllers/resources"
@@ -22,17 +25,26 @@ type MLEngine interface {
 	Deployment(owner metav1.Object) (*appsv1.Deployment, error)
 }
 
-func Reconcile(sd v1alpha1.AIDeployment, ctx context.Context, c ctrlClient.Client, mle MLEngine) (int, error) {
+func Reconcile(
+	sd v1alpha1.AIDeployment,
+	ctx context.Context,
+	c ctrlClient.Client,
+	mle MLEngine,
+	res registry.Resolver,
+	secrets ctrlClient.Reader,
+) (int, error) {
 	requeue := 0
+	lg := log.FromContext(ctx)
 
 	// Generate a Deployment from the Engine
+	_, span := tracing.Start(ctx, "MLEngine.Deployment", attribute.String("engine", string(sd.Spec.Engine.Name)))
 	deployment, err := mle.Deployment(&sd.ObjectMeta)
+	tracing.End(span, err)
 	if err != nil {
 		return 0, err
 	}
 
-	container := findContainerEngine(deployment)
-	if container != nil {
+	for _, container := range engineContainers(&deployment.Spec.Template.Spec) {
 		container.Args = append(container.Args, sd.Spec.Args...)
 	}
 
@@ -42,37 +54,79 @@ func Reconcile(sd v1alpha1.AIDeployment, ctx context.Context, c ctrlClient.Clien
 		return 0, err
 	}
 
-	d := &appsv1.Deployment{}
-	// try to find if a deployment already exists
-	if err := c.Get(ctx, types.NamespacedName{Namespace: sd.GetNamespace(), Name: sd.GetName()}, d); err != nil {
-		if apierrors.IsNotFound(err) { // Create a deployment
-			log.Info("Creating deployment", deployment.Namespace, ":", deployment.Name)
+	mirrorImages(&deployment.Spec.Template.Spec)
+	pinImages(ctx, res, secrets, &sd, &deployment.Spec.Template.Spec)
+
+	if err := reconcileWorkers(ctx, c, &sd, mle); err != nil {
+		return 0, err
+	}
+
+	if sd.Spec.Workload == v1alpha1.WorkloadKServe {
+		return reconcileInferenceService(ctx, c, &sd, deployment, mle.Port())
+	}
+
+	if sd.Spec.Deployment.Kind == v1alpha1.DeploymentKindStatefulSet {
+		sts, err := desiredStatefulSet(&sd, deployment)
+		if err != nil {
+			return 0, err
+		}
+
+		lg.V(1).Info("Applying statefulset", "namespace", sts.Namespace, "name", sts.Name)
+		conflict, err := applyStatefulSet(ctx, c, sts)
+		if err != nil {
+			return 0, err
+		}
+		if conflict {
+			lg.Info("StatefulSet changed during update, requeueing")
+			return 1, nil
+		}
+
+		if err := deleteIfExists(ctx, c, &sd, deployment.Namespace, deployment.Name, &appsv1.Deployment{}); err != nil {
+			return 0, err
+		}
+
+		re, err := updateAvailability(ctx, c, &sd, sts.Status.AvailableReplicas)
+		if err != nil {
+			return 0, err
+		}
+		requeue = re
+	} else {
+		d := &appsv1.Deployment{}
+		// try to find if a deployment already exists
+		if err := c.Get(ctx, types.NamespacedName{Namespace: sd.GetNamespace(), Name: sd.GetName()}, d); err != nil {
+			if apierrors.IsNotFound(err) { // Create a deployment
+				lg.Info("Creating deployment", "namespace", deployment.Namespace, "name", deployment.Name)
+				d = deployment.DeepCopy()
+				if err := c.Create(ctx, d); err != nil {
+					return 0, err
+				}
+			} else {
+				return 0, err
+			}
+		} else { // Update a deployment
+			deployment.ResourceVersion = d.ResourceVersion
 			d = deployment.DeepCopy()
-			if err := c.Create(ctx, d); err != nil {
+
+			lg.V(1).Info("Updating deployment", "namespace", deployment.Namespace, "name", deployment.Name)
+			if err := c.Update(ctx, d); err != nil {
+				if apierrors.IsConflict(err) {
+					lg.Info("Deployment changed during update, requeueing")
+					return 1, nil
+				}
 				return 0, err
 			}
-		} else {
-			return 0, err
 		}
-	} else { // Update a deployment
-		deployment.ResourceVersion = d.ResourceVersion
-		d = deployment.DeepCopy()
-
-		log.Debug("Updating deployment ", deployment.Namespace, ":", deployment.Name)
-		if err := c.Update(ctx, d); err != nil {
-			if apierrors.IsConflict(err) {
-				log.Info("Deployment changed during update, requeueing")
-				return 1, nil
-			}
+
+		if err := deleteIfExists(ctx, c, &sd, deployment.Namespace, deployment.Name, &appsv1.StatefulSet{}); err != nil {
 			return 0, err
 		}
-	}
 
-	re, err := UpdateAIDeploymentStatus(ctx, c, &sd, d, "")
-	if err != nil {
-		return 0, err
+		re, err := UpdateAIDeploymentStatus(ctx, c, &sd, d, "")
+		if err != nil {
+			return 0, err
+		}
+		requeue = re
 	}
-	requeue = re
 
 	annotations := resources.GenDefaultAnnotation(sd.Name)
 	for k, v := range sd.Spec.Service.Annotations {
@@ -93,7 +147,7 @@ func Reconcile(sd v1alpha1.AIDeployment, ctx context.Context, c ctrlClient.Clien
 	// try to find if a svc already exists
 	if err := c.Get(ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}, svcK); err != nil {
 		if apierrors.IsNotFound(err) { // Create a deployment
-			log.Debug("Creating service ", svc.Namespace, ":", svc.Name)
+			lg.V(1).Info("Creating service", "namespace", svc.Namespace, "name", svc.Name)
 			svcK = svc.DeepCopy()
 			if err := c.Create(ctx, svcK); err != nil {
 				return 0, err
@@ -105,14 +159,14 @@ func Reconcile(sd v1alpha1.AIDeployment, ctx context.Context, c ctrlClient.Clien
 		svc.ResourceVersion = svcK.ResourceVersion
 		svcK = svc.DeepCopy()
 
-		log.Debug("Updating service ", svc.Namespace, ":", svc.Name)
+		lg.V(1).Info("Updating service", "namespace", svc.Namespace, "name", svc.Name)
 		if err := c.Update(ctx, svcK); err != nil {
 			return 0, err
 		}
 	}
 
 	if len(sd.Spec.Endpoint) == 0 {
-		log.Debug("No endpoint specified, skipping ingress creation")
+		lg.V(1).Info("No endpoint specified, skipping ingress creation")
 		return 0, nil
 	}
 
@@ -146,7 +200,7 @@ func Reconcile(sd v1alpha1.AIDeployment, ctx context.Context, c ctrlClient.Clien
 	// try to find if an ingress already exists
 	if err := c.Get(ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}, ingressK); err != nil {
 		if apierrors.IsNotFound(err) {
-			log.Debug("Creating ingress ", ingress.Namespace, ":", ingress.Name)
+			lg.V(1).Info("Creating ingress", "namespace", ingress.Namespace, "name", ingress.Name)
 			ingressK = ingress.DeepCopy()
 			if err := c.Create(ctx, ingressK); err != nil {
 				return 0, err
@@ -157,15 +211,13 @@ func Reconcile(sd v1alpha1.AIDeployment, ctx context.Context, c ctrlClient.Clien
 	} else { // Update a deployment
 		ingress.ResourceVersion = ingressK.ResourceVersion
 		ingressK = ingress.DeepCopy()
-		log.Debug("Updating ingress ", ingress.Namespace, ":", ingress.Name)
+		lg.V(1).Info("Updating ingress", "namespace", ingress.Namespace, "name", ingress.Name)
 		if err := c.Update(ctx, ingressK); err != nil {
 			return 0, err
 		}
 	}
 
-	log.Debug(
-		"Reconcile completed: ", sd.Name, " in namespace: ", sd.Namespace,
-	)
+	lg.V(1).Info("Reconcile completed", "namespace", sd.Namespace, "name", sd.Name)
 
 	return requeue, nil
 }
@@ -190,12 +242,24 @@ func UpdateAIDeploymentStatus(
 		return 0, nil
 	}
 
-	// The status of the Deployment might not be immediately available after the
-	// Deployment resource is created or updated, requeue to check the deployment
-	// status again after 3 seconds
+	return updateAvailability(ctx, c, aiDep, deployment.Status.AvailableReplicas)
+}
+
+// updateAvailability sets the AI deployment's status from the number of
+// available replicas of its workload
+func updateAvailability(
+	ctx context.Context,
+	c ctrlClient.Client,
+	aiDeployment *v1alpha1.AIDeployment,
+	availableReplicas int32,
+) (int, error) {
+	aiDep := aiDeployment.DeepCopy()
+
+	// The status of the workload might not be immediately available after it
+	// is created or updated, requeue to check the status again after 3 seconds
 	requeue := 3
 	aiDep.Status.Status = constants.NotReady
-	if deployment.Status.AvailableReplicas > 0 {
+	if availableReplicas > 0 {
 		aiDep.Status.Status = constants.Ready
 		requeue = 0
 	}
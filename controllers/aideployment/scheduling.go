@@ -2,7 +2,9 @@ package aideployment
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/premAI-io/prem-operator/controllers/config"
 	"github.com/premAI-io/prem-operator/controllers/constants"
 	"github.com/premAI-io/prem-operator/pkg/utils"
 
@@ -64,51 +66,124 @@ func neededGPUs(deploy a1.Deployment) (resource.Quantity, error) {
 	return gpus, nil
 }
 
-func findContainerEngine(appDeployment *appsv1.Deployment) (engineContainer *v1.Container) {
-	for i, c := range appDeployment.Spec.Template.Spec.Containers {
+// GPUsPerPod is the number of GPUs given to each of the engine's pods
+func GPUsPerPod(spec a1.AIDeploymentSpec) (int64, error) {
+	gpus, err := neededGPUs(spec.Deployment)
+	if err != nil {
+		return 0, err
+	}
+
+	// Each node of a distributed deployment has the same number of GPUs
+	if dist := spec.Distributed; dist != nil {
+		if gpus.IsZero() {
+			return 0, fmt.Errorf("a distributed deployment needs a CUDA accelerator")
+		}
+		return int64(dist.GPUsPerNode), nil
+	}
+
+	return gpus.Value(), nil
+}
+
+func findEngineContainer(pod *v1.PodSpec) *v1.Container {
+	for i, c := range pod.Containers {
 		if c.Name == constants.ContainerEngineName {
-			engineContainer = &appDeployment.Spec.Template.Spec.Containers[i]
-			return
+			return &pod.Containers[i]
+		}
+	}
+	return nil
+}
+
+// engineContainers returns the engine container or, when the engine runs a
+// container for each model, the model containers
+func engineContainers(pod *v1.PodSpec) []*v1.Container {
+	if c := findEngineContainer(pod); c != nil {
+		return []*v1.Container{c}
+	}
+
+	var cs []*v1.Container
+	for i, c := range pod.Containers {
+		if strings.HasPrefix(c.Name, constants.ContainerModelPrefix) {
+			cs = append(cs, &pod.Containers[i])
 		}
 	}
-	return
+	return cs
+}
+
+// splitResources divides the resources, apart from the GPUs which are set
+// separately, between n containers. CPU is split in millicores and the others
+// in whole units, rounding down.
+func splitResources(resources v1.ResourceList, n int64) v1.ResourceList {
+	if n == 1 || resources == nil {
+		return resources
+	}
+
+	split := v1.ResourceList{}
+	for name, q := range resources {
+		switch name {
+		case constants.NvidiaGPULabel:
+			split[name] = q
+		case v1.ResourceCPU:
+			split[name] = *resource.NewMilliQuantity(q.MilliValue()/n, q.Format)
+		default:
+			split[name] = *resource.NewQuantity(q.Value()/n, q.Format)
+		}
+	}
+
+	return split
 }
 
 func AddSchedulingProperties(appDeployment *appsv1.Deployment, AIDeployment a1.AIDeploymentSpec) error {
-	addTopologySpread(&appDeployment.Spec.Template)
+	return addPodSchedulingProperties(&appDeployment.Spec.Template, AIDeployment)
+}
 
-	pod := &appDeployment.Spec.Template.Spec
+func addPodSchedulingProperties(tmpl *v1.PodTemplateSpec, AIDeployment a1.AIDeploymentSpec) error {
+	addTopologySpread(tmpl)
+
+	pod := &tmpl.Spec
 	pod.NodeSelector = utils.MergeMaps(pod.NodeSelector, AIDeployment.Deployment.NodeSelector)
 
-	gpus, err := neededGPUs(AIDeployment.Deployment)
+	n, err := GPUsPerPod(AIDeployment)
 	if err != nil {
 		return err
 	}
-
-	engineContainer := findContainerEngine(appDeployment)
-	if engineContainer == nil {
+	containers := engineContainers(pod)
+	if len(containers) == 0 {
 		return fmt.Errorf("no container named %s found in deployment", constants.ContainerEngineName)
 	}
 
-	engineContainer.Resources.Requests = utils.MergeMaps(
-		engineContainer.Resources.Requests,
-		AIDeployment.Deployment.Resources.Requests,
-	)
-
-	engineContainer.Resources.Limits = utils.MergeMaps(
-		engineContainer.Resources.Limits,
-		AIDeployment.Deployment.Resources.Limits,
-	)
-
-	if !gpus.IsZero() {
-		engineContainer.Resources.Requests[constants.NvidiaGPULabel] = gpus
-		engineContainer.Resources.Limits[constants.NvidiaGPULabel] = gpus
-
-		if pod.RuntimeClassName == nil {
-			runtimeClassName := "nvidia"
-			pod.RuntimeClassName = &runtimeClassName
+	// The resources are for the pod, so they are split between the model
+	// containers. The GPUs have to be split evenly.
+	models := int64(len(containers))
+	if n%models != 0 {
+		return fmt.Errorf("%d GPUs can't be split evenly between %d models", n, models)
+	}
+	gpus := *resource.NewQuantity(n/models, resource.DecimalSI)
+
+	defaults := config.Get().Engine(AIDeployment.Engine.Name)
+
+	for _, engineContainer := range containers {
+		engineContainer.Resources.Requests = utils.MergeMaps(
+			splitResources(defaults.Resources.Requests, models),
+			engineContainer.Resources.Requests,
+			splitResources(AIDeployment.Deployment.Resources.Requests, models),
+		)
+
+		engineContainer.Resources.Limits = utils.MergeMaps(
+			splitResources(defaults.Resources.Limits, models),
+			engineContainer.Resources.Limits,
+			splitResources(AIDeployment.Deployment.Resources.Limits, models),
+		)
+
+		if !gpus.IsZero() {
+			engineContainer.Resources.Requests[constants.NvidiaGPULabel] = gpus
+			engineContainer.Resources.Limits[constants.NvidiaGPULabel] = gpus
 		}
 	}
 
+	if !gpus.IsZero() && pod.RuntimeClassName == nil {
+		runtimeClassName := "nvidia"
+		pod.RuntimeClassName = &runtimeClassName
+	}
+
 	return nil
 }
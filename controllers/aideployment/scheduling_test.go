@@ -0,0 +1,95 @@
+package aideployment
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+var _ = Describe("addPodSchedulingProperties", func() {
+	var spec v1alpha1.AIDeploymentSpec
+
+	BeforeEach(func() {
+		spec = v1alpha1.AIDeploymentSpec{
+			Engine: v1alpha1.AIEngine{Name: v1alpha1.AIEngineNameVLLM},
+			Deployment: v1alpha1.Deployment{
+				Accelerator: &v1alpha1.Accelerator{Interface: v1alpha1.AcceleratorInterfaceCUDA},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						constants.NvidiaGPULabel: resource.MustParse("4"),
+						v1.ResourceMemory:        resource.MustParse("32Gi"),
+					},
+				},
+			},
+		}
+	})
+
+	gpus := func(c v1.Container) int64 {
+		q := c.Resources.Limits[constants.NvidiaGPULabel]
+		return q.Value()
+	}
+
+	It("gives the GPUs to the engine container", func() {
+		tmpl := &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: constants.ContainerEngineName},
+		}}}
+
+		Expect(addPodSchedulingProperties(tmpl, spec)).To(Succeed())
+		Expect(gpus(tmpl.Spec.Containers[0])).To(Equal(int64(4)))
+	})
+
+	It("splits the GPUs between the model containers", func() {
+		tmpl := &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: constants.ContainerModelPrefix + "0"},
+			{Name: constants.ContainerModelPrefix + "1"},
+			{Name: constants.ContainerRouterName},
+		}}}
+
+		Expect(addPodSchedulingProperties(tmpl, spec)).To(Succeed())
+		for _, c := range tmpl.Spec.Containers[:2] {
+			Expect(gpus(c)).To(Equal(int64(2)))
+		}
+		Expect(tmpl.Spec.Containers[2].Resources.Limits).To(BeEmpty())
+	})
+
+	It("splits the other resources so the pod requests what was asked for", func() {
+		spec.Deployment.Resources.Requests = v1.ResourceList{
+			constants.NvidiaGPULabel: resource.MustParse("3"),
+			v1.ResourceMemory:        resource.MustParse("48Gi"),
+			v1.ResourceCPU:           resource.MustParse("1500m"),
+		}
+		tmpl := &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: constants.ContainerModelPrefix + "0"},
+			{Name: constants.ContainerModelPrefix + "1"},
+			{Name: constants.ContainerModelPrefix + "2"},
+		}}}
+
+		Expect(addPodSchedulingProperties(tmpl, spec)).To(Succeed())
+
+		total := v1.ResourceList{}
+		for _, c := range tmpl.Spec.Containers {
+			for name, q := range c.Resources.Requests {
+				sum := total[name]
+				sum.Add(q)
+				total[name] = sum
+			}
+		}
+		Expect(total.Name(constants.NvidiaGPULabel, resource.DecimalSI).Value()).To(Equal(int64(3)))
+		Expect(total.Memory().Cmp(resource.MustParse("48Gi"))).To(BeZero())
+		Expect(total.Cpu().Cmp(resource.MustParse("1500m"))).To(BeZero())
+	})
+
+	It("rejects GPUs which can't be split evenly", func() {
+		tmpl := &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: constants.ContainerModelPrefix + "0"},
+			{Name: constants.ContainerModelPrefix + "1"},
+			{Name: constants.ContainerModelPrefix + "2"},
+		}}}
+
+		Expect(addPodSchedulingProperties(tmpl, spec)).To(MatchError(ContainSubstring("split evenly")))
+	})
+})
@@ -0,0 +1,122 @@
+package aideployment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+)
+
+// modelsVolume is the name of the volume the engines store models on
+const modelsVolume = "models"
+
+// desiredStatefulSet turns the engine's Deployment into a StatefulSet whose
+// models volume is a PersistentVolumeClaim for each replica
+func desiredStatefulSet(sd *v1alpha1.AIDeployment, deployment *appsv1.Deployment) (*appsv1.StatefulSet, error) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: deployment.ObjectMeta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    deployment.Spec.Replicas,
+			Selector:    deployment.Spec.Selector,
+			Template:    *deployment.Spec.Template.DeepCopy(),
+			ServiceName: deployment.Name,
+			// The replicas don't depend on each other
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+		},
+	}
+
+	if err := persistModels(sd, sts); err != nil {
+		return nil, err
+	}
+
+	return sts, nil
+}
+
+// persistModels replaces the models volume of the StatefulSet's pods with a
+// PersistentVolumeClaim for each replica. The engine's containers mount the
+// volume by name, so it is mounted wherever the engine expects its models.
+func persistModels(sd *v1alpha1.AIDeployment, sts *appsv1.StatefulSet) error {
+	claim := sd.Spec.Deployment.VolumeClaimTemplate
+	if claim == nil {
+		return fmt.Errorf("a volumeClaimTemplate is needed when the deployment kind is StatefulSet")
+	}
+	if _, ok := claim.Resources.Requests[v1.ResourceStorage]; !ok {
+		return fmt.Errorf("the volumeClaimTemplate must request storage for the models")
+	}
+
+	claim = claim.DeepCopy()
+	if len(claim.AccessModes) == 0 {
+		claim.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+	}
+
+	pod := &sts.Spec.Template.Spec
+	n := len(pod.Volumes)
+	pod.Volumes = slices.DeleteFunc(pod.Volumes, func(v v1.Volume) bool {
+		return v.Name == modelsVolume
+	})
+	if len(pod.Volumes) == n {
+		return fmt.Errorf("the %s engine doesn't keep its models on a volume, it can't be run as a StatefulSet", sd.Spec.Engine.Name)
+	}
+
+	sts.Spec.VolumeClaimTemplates = []v1.PersistentVolumeClaim{{
+		ObjectMeta: metav1.ObjectMeta{Name: modelsVolume},
+		Spec:       *claim,
+	}}
+
+	return nil
+}
+
+// applyStatefulSet creates or updates the StatefulSet, conflict is true if it
+// was changed during the update
+func applyStatefulSet(ctx context.Context, c ctrlClient.Client, sts *appsv1.StatefulSet) (conflict bool, err error) {
+	current := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, ctrlClient.ObjectKeyFromObject(sts), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+
+		return false, c.Create(ctx, sts)
+	}
+
+	sts.ResourceVersion = current.ResourceVersion
+	// The claim templates can't be changed once the StatefulSet exists
+	sts.Spec.VolumeClaimTemplates = current.Spec.VolumeClaimTemplates
+
+	if err := c.Update(ctx, sts); err != nil {
+		if apierrors.IsConflict(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return false, nil
+}
+
+// deleteIfExists deletes an object left behind when the AIDeployment's
+// workload or kind was changed. Objects with the same name which the
+// AIDeployment doesn't control, such as the Service KServe creates for an
+// InferenceService, are left alone.
+func deleteIfExists(
+	ctx context.Context,
+	c ctrlClient.Client,
+	owner metav1.Object,
+	namespace, name string,
+	o ctrlClient.Object,
+) error {
+	if err := c.Get(ctx, ctrlClient.ObjectKey{Namespace: namespace, Name: name}, o); err != nil {
+		return ctrlClient.IgnoreNotFound(err)
+	}
+
+	if !metav1.IsControlledBy(o, owner) {
+		return nil
+	}
+
+	return ctrlClient.IgnoreNotFound(c.Delete(ctx, o))
+}
@@ -0,0 +1,124 @@
+package aideployment
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+var _ = Describe("desiredStatefulSet", func() {
+	var (
+		sd         *v1alpha1.AIDeployment
+		deployment *appsv1.Deployment
+	)
+
+	BeforeEach(func() {
+		sd = &v1alpha1.AIDeployment{
+			Spec: v1alpha1.AIDeploymentSpec{
+				Engine: v1alpha1.AIEngine{Name: v1alpha1.AIEngineNameVLLM},
+				Deployment: v1alpha1.Deployment{
+					Kind: v1alpha1.DeploymentKindStatefulSet,
+					VolumeClaimTemplate: &v1.PersistentVolumeClaimSpec{
+						Resources: v1.VolumeResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("50Gi")},
+						},
+					},
+				},
+			},
+		}
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "vllm", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{
+							Name:         "engine",
+							VolumeMounts: []v1.VolumeMount{{Name: modelsVolume, MountPath: "/root/.cache/huggingface"}},
+						}},
+						Volumes: []v1.Volume{
+							{Name: modelsVolume, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+							{Name: "shm", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("replaces the models volume with a claim for each replica", func() {
+		sts, err := desiredStatefulSet(sd, deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sts.Name).To(Equal("vllm"))
+		Expect(sts.Spec.ServiceName).To(Equal("vllm"))
+		Expect(sts.Spec.Template.Spec.Volumes).To(ConsistOf(HaveField("Name", "shm")))
+		Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(
+			v1.VolumeMount{Name: modelsVolume, MountPath: "/root/.cache/huggingface"},
+		))
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Name).To(Equal(modelsVolume))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Spec.AccessModes).To(ConsistOf(v1.ReadWriteOnce))
+	})
+
+	It("needs the claim to request storage", func() {
+		sd.Spec.Deployment.VolumeClaimTemplate.Resources.Requests = nil
+
+		_, err := desiredStatefulSet(sd, deployment)
+		Expect(err).To(MatchError(ContainSubstring("request storage")))
+	})
+
+	It("rejects engines without a models volume", func() {
+		deployment.Spec.Template.Spec.Volumes = deployment.Spec.Template.Spec.Volumes[1:]
+
+		_, err := desiredStatefulSet(sd, deployment)
+		Expect(err).To(MatchError(ContainSubstring("can't be run as a StatefulSet")))
+	})
+	It("gives the workers of a distributed deployment a claim as well", func(ctx SpecContext) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		sd.Name, sd.Namespace = "vllm", "default"
+		sd.Spec.Distributed = &v1alpha1.Distributed{Nodes: 2, GPUsPerNode: 1}
+		sd.Spec.Deployment.Accelerator = &v1alpha1.Accelerator{Interface: v1alpha1.AcceleratorInterfaceCUDA}
+		deployment.Spec.Template.Spec.Containers[0].Name = constants.ContainerEngineName
+		Expect(reconcileWorkers(ctx, c, sd, &fakeDistributed{template: deployment.Spec.Template})).To(Succeed())
+
+		workers := &appsv1.StatefulSet{}
+		Expect(c.Get(ctx, ctrlClient.ObjectKey{Namespace: "default", Name: "vllm-workers"}, workers)).To(Succeed())
+		Expect(workers.Spec.Template.Spec.Volumes).To(ConsistOf(HaveField("Name", "shm")))
+		Expect(workers.Spec.VolumeClaimTemplates).To(ConsistOf(HaveField("Name", modelsVolume)))
+	})
+})
+
+// fakeDistributed is a DistributedEngine whose workers run the template
+type fakeDistributed struct {
+	template v1.PodTemplateSpec
+}
+
+func (f *fakeDistributed) Port() int32 { return 8000 }
+
+func (f *fakeDistributed) Deployment(_ metav1.Object) (*appsv1.Deployment, error) {
+	return nil, nil
+}
+
+func (f *fakeDistributed) Workers(owner metav1.Object) (*appsv1.StatefulSet, error) {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: owner.GetName() + "-workers", Namespace: owner.GetNamespace()},
+		Spec:       appsv1.StatefulSetSpec{Template: *f.template.DeepCopy()},
+	}, nil
+}
+
+func (f *fakeDistributed) HeadPorts() []v1.ServicePort {
+	return []v1.ServicePort{{Name: "gcs", Port: 6379}}
+}
@@ -19,33 +19,57 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/premAI-io/prem-operator/api/v1alpha1"
 	"github.com/premAI-io/prem-operator/controllers/aideployment"
 	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
 	"github.com/premAI-io/prem-operator/controllers/engines"
+	"github.com/premAI-io/prem-operator/controllers/metrics"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+	"github.com/premAI-io/prem-operator/pkg/registry"
+	"github.com/premAI-io/prem-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // AIDeploymentReconciler reconciles a AIDeployment object
 type AIDeploymentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads directly from the API server, it is used for objects
+	// we don't want to keep in the cache such as Pods and pull secrets
+	APIReader client.Reader
+	// ConfigChanges, if set, requeues every AIDeployment when the operator
+	// config is reloaded
+	ConfigChanges <-chan event.GenericEvent
+	// ImageResolver, if set, is used to pin the engine images to digests
+	ImageResolver registry.Resolver
 }
 
 //+kubebuilder:rbac:groups=premlabs.io,resources=aideployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=premlabs.io,resources=aideployments/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=premlabs.io,resources=aideployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=services,verbs=create;get;list;update;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=create;get;list;update;watch;delete
+//+kubebuilder:rbac:groups=serving.kserve.io,resources=inferenceservices,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=premlabs.io,resources=aimodelmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=premlabs.io,resources=aienginetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -56,55 +80,62 @@ type AIDeploymentReconciler struct {
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.0/pkg/reconcile
-func (r *AIDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+func (r *AIDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, reterr error) {
+	ctx, span := tracing.Start(
+		ctx,
+		"AIDeployment.Reconcile",
+		attribute.String("k8s.namespace", req.Namespace),
+		attribute.String("k8s.name", req.Name),
+	)
+	defer func() { tracing.End(span, reterr) }()
 
 	// Creates a deployment targeting a service
 	var ent v1alpha1.AIDeployment
 	if err := r.Get(ctx, req.NamespacedName, &ent); err != nil {
 		if apierrors.IsNotFound(err) {
+			metrics.ForgetModelDownloads(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
-	var (
-		mlEngine aideployment.MLEngine
-		err      error
-	)
+	start := time.Now()
+	span.SetAttributes(attribute.String("engine", string(ent.Spec.Engine.Name)))
+	defer func() {
+		engine := string(ent.Spec.Engine.Name)
+		metrics.ReconcileDuration.WithLabelValues(engine).Observe(time.Since(start).Seconds())
+		if reterr != nil {
+			metrics.ReconcileErrors.WithLabelValues(engine).Inc()
+		}
+	}()
 
-	models, err := aimodelmap.Resolve(&ent, ctx, r.Client)
+	engine, err := engines.Lookup(ent.Spec.Engine.Name)
 	if err != nil {
-		_, err1 := aideployment.UpdateAIDeploymentStatus(
-			ctx, r.Client, &ent, nil, err.Error(),
-		)
-
-		return ctrl.Result{}, fmt.Errorf("%w: %v", err, err1)
+		return ctrl.Result{}, r.setFailed(ctx, &ent, err)
 	}
 
-	switch ent.Spec.Engine.Name {
-	case v1alpha1.AIEngineNameTriton:
-		mlEngine = engines.NewTriton(&ent, models)
-	case v1alpha1.AIEngineNameLocalai:
-		mlEngine = engines.NewLocalAI(&ent, models)
-	case v1alpha1.AIEngineNameVLLM:
-		mlEngine, err = engines.NewVllmAi(&ent, models)
-	case v1alpha1.AIEngineNameGeneric:
-		mlEngine = engines.NewGeneric(&ent)
-	case v1alpha1.AIEngineNameDeepSpeedMii:
-		mlEngine, err = engines.NewDeepSpeedMii(&ent, models)
-	default:
-		err = fmt.Errorf("unknown engine %s", ent.Spec.Engine.Name)
+	// The warnings are saved with whatever status the reconcile sets. Only
+	// the new ones are logged, the others were when they first appeared.
+	previous := ent.Status.Warnings
+	ent.Status.Warnings = engine.Warnings(&ent)
+	for _, w := range ent.Status.Warnings {
+		if !slices.Contains(previous, w) {
+			log.FromContext(ctx).Info("AIDeployment warning", "warning", w)
+		}
 	}
+
+	models, err := aimodelmap.Resolve(&ent, ctx, r.Client, engine.ModelMapVariants)
 	if err != nil {
-		_, err1 := aideployment.UpdateAIDeploymentStatus(
-			ctx, r.Client, &ent, nil, err.Error(),
-		)
+		return ctrl.Result{}, r.setFailed(ctx, &ent, err)
+	}
 
-		return ctrl.Result{}, fmt.Errorf("%w: %v", err, err1)
+	mlEngine, err := engine.Create(ctx, r.Client, &ent, models)
+	if err != nil {
+		return ctrl.Result{}, r.setFailed(ctx, &ent, err)
 	}
 
-	requeue, err := aideployment.Reconcile(ent, ctx, r.Client, mlEngine)
+	requeue, err := aideployment.Reconcile(ent, ctx, r.Client, mlEngine, r.ImageResolver, r.APIReader)
+	r.observeModelDownloads(ctx, &ent)
 	if requeue > 0 {
 		return ctrl.Result{RequeueAfter: time.Second * time.Duration(requeue)}, err
 	}
@@ -123,9 +154,91 @@ func (r *AIDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
+// setFailed records the error in the AIDeployment's status
+func (r *AIDeploymentReconciler) setFailed(ctx context.Context, ent *v1alpha1.AIDeployment, err error) error {
+	_, err1 := aideployment.UpdateAIDeploymentStatus(
+		ctx, r.Client, ent, nil, err.Error(),
+	)
+
+	return fmt.Errorf("%w: %v", err, err1)
+}
+
+// observeModelDownloads records metrics about the model download init
+// containers. Failing to list the pods shouldn't fail the reconcile.
+func (r *AIDeploymentReconciler) observeModelDownloads(ctx context.Context, ent *v1alpha1.AIDeployment) {
+	pods := &v1.PodList{}
+	err := r.APIReader.List(
+		ctx,
+		pods,
+		client.InNamespace(ent.Namespace),
+		client.MatchingLabels(resources.GenDefaultLabels(ent.Name)),
+	)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list pods for model download metrics")
+		return
+	}
+
+	metrics.ObserveModelDownloads(
+		string(ent.Spec.Engine.Name),
+		client.ObjectKeyFromObject(ent),
+		pods.Items,
+	)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *AIDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.AIDeployment{}).
-		Complete(r)
+		Watches(
+			&v1alpha1.AIEngineTemplate{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForTemplate),
+		)
+
+	if r.ConfigChanges != nil {
+		b = b.WatchesRawSource(
+			&source.Channel{Source: r.ConfigChanges},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForAll),
+		)
+	}
+
+	return b.Complete(r)
+}
+
+// requestsForTemplate returns a request for every AIDeployment which uses
+// the AIEngineTemplate, templates are only used from their own namespace
+func (r *AIDeploymentReconciler) requestsForTemplate(ctx context.Context, o client.Object) []reconcile.Request {
+	list := &v1alpha1.AIDeploymentList{}
+	if err := r.List(ctx, list, client.InNamespace(o.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AIDeployments")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for i := range list.Items {
+		d := &list.Items[i]
+		ref := d.Spec.Engine.TemplateRef
+		if d.Spec.Engine.Name != v1alpha1.AIEngineNameTemplate || ref == nil || ref.Name != o.GetName() {
+			continue
+		}
+
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(d)})
+	}
+
+	return reqs
+}
+
+// requestsForAll returns a request for every AIDeployment
+func (r *AIDeploymentReconciler) requestsForAll(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := &v1alpha1.AIDeploymentList{}
+	if err := r.List(ctx, list); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list AIDeployments")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+
+	return reqs
 }
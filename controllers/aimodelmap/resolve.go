@@ -7,27 +7,44 @@ import (
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/config"
+	"github.com/premAI-io/prem-operator/pkg/tracing"
 	"github.com/premAI-io/prem-operator/pkg/utils"
 )
 
+// VariantsFunc returns an engine's variants from an AIModelMap
+type VariantsFunc func(spec *a1.AIModelMapSpec, engine *a1.AIEngine) []a1.AIModelVariant
+
 type ResolvedModel struct {
-	Name     string
-	Variant  string
-	HostName string
-	Spec     a1.AIModelSpec
+	Name string
+	// The namespace of the AIModelMap, empty for an inline model
+	Namespace string
+	Variant   string
+	HostName  string
+	Spec      a1.AIModelSpec
 }
 
-// Resolve resolves the models in the deployment
-func Resolve(d *a1.AIDeployment, ctx context.Context, c ctrlClient.Client) ([]ResolvedModel, error) {
-	ms := make([]ResolvedModel, 0, len(d.Spec.Models))
+// Resolve resolves the models in the deployment, variants is nil if the
+// deployment's engine can't use AIModelMaps
+func Resolve(
+	d *a1.AIDeployment,
+	ctx context.Context,
+	c ctrlClient.Client,
+	variants VariantsFunc,
+) (ms []ResolvedModel, err error) {
+	ctx, span := tracing.Start(ctx, "aimodelmap.Resolve")
+	defer func() { tracing.End(span, err) }()
+
+	ms = make([]ResolvedModel, 0, len(d.Spec.Models))
 
 	for _, m := range d.Spec.Models {
-		rm, err := resolveOne(&m, d, ctx, c)
+		rm, err := resolveOne(&m, d, ctx, c, variants)
 		if err != nil {
 			return nil, err
 		}
 
 		if rm != nil {
+			rm.Spec.Uri = config.Get().RewriteURL(rm.Spec.Uri)
 			ms = append(ms, *rm)
 		}
 	}
@@ -35,6 +52,25 @@ func Resolve(d *a1.AIDeployment, ctx context.Context, c ctrlClient.Client) ([]Re
 	return ms, nil
 }
 
+// ResolveModel resolves a model the deployment uses besides its models, such
+// as a draft model
+func ResolveModel(
+	m *a1.AIModel,
+	d *a1.AIDeployment,
+	ctx context.Context,
+	c ctrlClient.Client,
+	variants VariantsFunc,
+) (*ResolvedModel, error) {
+	rm, err := resolveOne(m, d, ctx, c, variants)
+	if err != nil {
+		return nil, err
+	}
+
+	rm.Spec.Uri = config.Get().RewriteURL(rm.Spec.Uri)
+
+	return rm, nil
+}
+
 func findVariant(mv []a1.AIModelVariant, name string) *a1.AIModelSpec {
 	for _, v := range mv {
 		if v.Variant == name {
@@ -68,10 +104,40 @@ func mergeModelSpecs(primary *a1.AIModelSpec, secondary *a1.AIModelSpec) *a1.AIM
 		result.EngineConfigFile = secondary.EngineConfigFile
 	}
 
+	if result.TensorRTLLM == nil {
+		result.TensorRTLLM = secondary.TensorRTLLM.DeepCopy()
+	}
+
+	if result.ServedName == "" {
+		result.ServedName = secondary.ServedName
+	}
+
+	if len(result.Aliases) == 0 {
+		result.Aliases = append([]string(nil), secondary.Aliases...)
+	}
+
+	if result.ChatTemplate == "" {
+		result.ChatTemplate = secondary.ChatTemplate
+	}
+
+	if result.TokenizerFamily == "" {
+		result.TokenizerFamily = secondary.TokenizerFamily
+	}
+
+	if len(result.Adapters) == 0 {
+		result.Adapters = append([]a1.LoRAAdapter(nil), secondary.Adapters...)
+	}
+
 	return result
 }
 
-func resolveOne(m *a1.AIModel, d *a1.AIDeployment, ctx context.Context, c ctrlClient.Client) (*ResolvedModel, error) {
+func resolveOne(
+	m *a1.AIModel,
+	d *a1.AIDeployment,
+	ctx context.Context,
+	c ctrlClient.Client,
+	variantsOf VariantsFunc,
+) (*ResolvedModel, error) {
 	if m.ModelMapRef == nil {
 		return &ResolvedModel{
 			Name:     d.Name,
@@ -95,38 +161,27 @@ func resolveOne(m *a1.AIModel, d *a1.AIDeployment, ctx context.Context, c ctrlCl
 		return nil, fmt.Errorf("deployment %s/%s has modelMapRef with no variant", d.Namespace, d.Name)
 	}
 
+	if variantsOf == nil {
+		return nil, fmt.Errorf("deployment %s/%s: Can't specify a model map with the %s engine", d.Namespace, d.Name, d.Spec.Engine.Name)
+	}
+
 	mm := &a1.AIModelMap{}
 	if err := c.Get(ctx, ctrlClient.ObjectKey{Namespace: namespace, Name: name}, mm); err != nil {
 		return nil, err
 	}
 
-	var variants []a1.AIModelVariant
-	switch d.Spec.Engine.Name {
-	case a1.AIEngineNameTriton:
-		variants = mm.Spec.TensorRT
-	case a1.AIEngineNameLocalai:
-		variants = mm.Spec.Localai
-	case a1.AIEngineNameVLLM:
-		variants = mm.Spec.Vllm
-	case a1.AIEngineNameDeepSpeedMii:
-		variants = mm.Spec.DeepSpeedMii
-	case a1.AIEngineNameGeneric:
-		return nil, fmt.Errorf("deployment %s/%s: Can't specify a model with generic engine", d.Namespace, d.Name)
-	default:
-		return nil, fmt.Errorf("deployment %s/%s has unknown engine %s", d.Namespace, d.Name, d.Spec.Engine.Name)
-	}
-
-	variant := findVariant(variants, m.ModelMapRef.Variant)
+	variant := findVariant(variantsOf(&mm.Spec, &d.Spec.Engine), m.ModelMapRef.Variant)
 	if variant == nil {
-		return nil, fmt.Errorf("deployment %s/%s has no model variant %s for %s", d.Namespace, d.Name, m.ModelMapRef.Variant, a1.AIEngineNameLocalai)
+		return nil, fmt.Errorf("deployment %s/%s has no model variant %s for %s", d.Namespace, d.Name, m.ModelMapRef.Variant, d.Spec.Engine.Name)
 	}
 
 	merged := mergeModelSpecs(&m.AIModelSpec, variant)
 
 	return &ResolvedModel{
-		Name:     m.ModelMapRef.Name,
-		Variant:  m.ModelMapRef.Variant,
-		HostName: utils.ToHostName(m.ModelMapRef.Name + "-" + m.ModelMapRef.Variant),
-		Spec:     *merged,
+		Name:      m.ModelMapRef.Name,
+		Namespace: namespace,
+		Variant:   m.ModelMapRef.Variant,
+		HostName:  utils.ToHostName(m.ModelMapRef.Name + "-" + m.ModelMapRef.Variant),
+		Spec:      *merged,
 	}, nil
 }
@@ -22,3 +22,10 @@ func SetEngineConfigFileData(cm *v1.ConfigMap, engineName a1.AIEngineName, varia
 	}
 	cm.Data[FmtConfigMapKey(engineName, variantName, constants.AIModelMapSpecEngineConfig)] = data
 }
+
+func SetChatTemplateData(cm *v1.ConfigMap, engineName a1.AIEngineName, variantName string, data string) {
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[FmtConfigMapKey(engineName, variantName, constants.AIModelMapSpecChatTemplate)] = data
+}
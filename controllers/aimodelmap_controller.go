@@ -31,6 +31,7 @@ import (
 	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
 	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
 	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
 )
 
 // AIModelMapReconciler reconciles a AIModelMap object
@@ -83,17 +84,13 @@ func (r *AIModelMapReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	newConfigMap := &corev1.ConfigMap{}
 	addedCount := 0
 
-	engines := []struct {
-		name     a1.AIEngineName
-		variants []a1.AIModelVariant
-	}{
-		{name: a1.AIEngineNameLocalai, variants: modelMap.Spec.Localai},
-		{name: a1.AIEngineNameVLLM, variants: modelMap.Spec.Vllm},
-		{name: a1.AIEngineNameGeneric, variants: modelMap.Spec.DeepSpeedMii},
-	}
+	for _, eng := range engines.All() {
+		if eng.ModelMapVariants == nil {
+			continue
+		}
 
-	for _, eng := range engines {
-		ac, err := addVariants(newConfigMap, eng.name, eng.variants)
+		variants := eng.ModelMapVariants(&modelMap.Spec, &a1.AIEngine{Name: eng.Name})
+		ac, err := addVariants(newConfigMap, eng.Name, variants)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -152,6 +149,11 @@ func addVariants(cm *corev1.ConfigMap, engineName a1.AIEngineName, variants []a1
 			aimodelmap.SetEngineConfigFileData(cm, engineName, v.Variant, v.EngineConfigFile)
 			addedCount += 1
 		}
+
+		if v.ChatTemplate != "" {
+			aimodelmap.SetChatTemplateData(cm, engineName, v.Variant, v.ChatTemplate)
+			addedCount += 1
+		}
 	}
 
 	return addedCount, nil
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+// Config is the operator wide configuration which is read from a file,
+// usually mounted from a ConfigMap, so that it can be changed without
+// rebuilding or restarting the operator
+type Config struct {
+	// Defaults for each engine, these are overridden by the AIDeployment
+	// +optional
+	Engines map[a1.AIEngineName]EngineDefaults `json:"engines,omitempty"`
+	// Maps a registry to the mirror used in its place, for example
+	// nvcr.io: registry.internal/nvcr. Images without a registry are on
+	// docker.io.
+	// +optional
+	RegistryMirrors map[string]string `json:"registryMirrors,omitempty"`
+	// Maps a URL prefix to its replacement in the http(s) URIs of models,
+	// the longest matching prefix is used
+	// +optional
+	URLRewrites map[string]string `json:"urlRewrites,omitempty"`
+}
+
+// EngineDefaults are the settings used by an engine when the AIDeployment
+// doesn't specify them
+type EngineDefaults struct {
+	// +optional
+	ImageRepository string `json:"imageRepository,omitempty"`
+	// +optional
+	ImageTag string `json:"imageTag,omitempty"`
+	// +optional
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Used when the AIDeployment doesn't set any pull secrets, in
+	// spec.deployment or its pod template
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Requests and limits for the engine container, these are merged with
+	// the resources set in the AIDeployment
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// builtin are the defaults used for any field the config file leaves empty
+var builtin = map[a1.AIEngineName]EngineDefaults{
+	a1.AIEngineNameLocalai: {
+		ImageRepository: constants.ImageRepositoryLocalai,
+		ImageTag:        constants.ImageTagLatest,
+	},
+	a1.AIEngineNameVLLM: {
+		ImageRepository: constants.ImageRepositoryVllm,
+		ImageTag:        constants.ImageTagLatest,
+	},
+	a1.AIEngineNameDeepSpeedMii: {
+		ImageRepository: constants.ImageRepositoryDeepSpeedMii,
+		ImageTag:        constants.ImageTagLatest,
+	},
+	a1.AIEngineNameTriton: {
+		ImageRepository: constants.ImageRepositoryTriton,
+		ImageTag:        constants.ImageTagTritonDefault,
+	},
+	a1.AIEngineNameTGI: {
+		ImageRepository: constants.ImageRepositoryTGI,
+		ImageTag:        constants.ImageTagLatest,
+	},
+	a1.AIEngineNameOllama: {
+		ImageRepository: constants.ImageRepositoryOllama,
+		ImageTag:        constants.ImageTagLatest,
+	},
+	a1.AIEngineNameSGLang: {
+		ImageRepository: constants.ImageRepositorySGLang,
+		ImageTag:        constants.ImageTagLatest,
+	},
+	// The tag depends on the accelerator
+	a1.AIEngineNameLlamaCpp: {
+		ImageRepository: constants.ImageRepositoryLlamaCpp,
+	},
+	a1.AIEngineNameTEI: {
+		ImageRepository: constants.ImageRepositoryTEI,
+	},
+	a1.AIEngineNameWhisper: {
+		ImageRepository: constants.ImageRepositoryWhisper,
+	},
+}
+
+var current atomic.Pointer[Config]
+
+func init() {
+	current.Store(&Config{})
+}
+
+// Get returns the config which is currently in use. It must not be modified.
+func Get() *Config {
+	return current.Load()
+}
+
+// Set replaces the config which is currently in use
+func Set(c *Config) {
+	current.Store(c)
+}
+
+// Parse reads a config from YAML or JSON and validates it
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("invalid operator config: %w", err)
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid operator config: %w", err)
+	}
+
+	return c, nil
+}
+
+// validate checks the values which the schema of the file doesn't
+func (c *Config) validate() error {
+	for name, d := range c.Engines {
+		if _, ok := builtin[name]; !ok {
+			return fmt.Errorf("unknown engine %s", name)
+		}
+
+		switch d.ImagePullPolicy {
+		case "", v1.PullAlways, v1.PullIfNotPresent, v1.PullNever:
+		default:
+			return fmt.Errorf("engine %s: unknown imagePullPolicy %s", name, d.ImagePullPolicy)
+		}
+
+		for _, s := range d.ImagePullSecrets {
+			if s.Name == "" {
+				return fmt.Errorf("engine %s: imagePullSecrets need a name", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Load reads the config from path and makes it current. A missing file is
+// treated as an empty config, so the ConfigMap holding it can be optional.
+func Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		Set(&Config{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	Set(c)
+	return nil
+}
+
+// Engine returns the defaults for an engine with the built in defaults
+// filling any fields which are not set in the config
+func (c *Config) Engine(name a1.AIEngineName) EngineDefaults {
+	d := c.Engines[name].deepCopy()
+	b := builtin[name]
+
+	if d.ImageRepository == "" {
+		d.ImageRepository = b.ImageRepository
+	}
+	if d.ImageTag == "" {
+		d.ImageTag = b.ImageTag
+	}
+	if d.ImagePullPolicy == "" {
+		d.ImagePullPolicy = v1.PullAlways
+	}
+	if d.ImagePullSecrets == nil {
+		d.ImagePullSecrets = b.ImagePullSecrets
+	}
+
+	return d
+}
+
+func (in EngineDefaults) deepCopy() EngineDefaults {
+	out := in
+	if in.ImagePullSecrets != nil {
+		out.ImagePullSecrets = make([]v1.LocalObjectReference, len(in.ImagePullSecrets))
+		copy(out.ImagePullSecrets, in.ImagePullSecrets)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+
+	return out
+}
@@ -0,0 +1,162 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/config"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+var _ = Describe("Config", func() {
+	AfterEach(func() {
+		config.Set(&config.Config{})
+	})
+
+	It("uses the built in defaults when the config is empty", func() {
+		d := config.Get().Engine(a1.AIEngineNameDeepSpeedMii)
+
+		Expect(d.ImageRepository).To(Equal(constants.ImageRepositoryDeepSpeedMii))
+		Expect(d.ImageTag).To(Equal(constants.ImageTagLatest))
+		Expect(d.ImagePullPolicy).To(Equal(v1.PullAlways))
+		Expect(d.ImagePullSecrets).To(BeEmpty())
+	})
+
+	It("merges the config file with the built in defaults", func() {
+		c, err := config.Parse([]byte(`
+engines:
+  vllm:
+    imageTag: v0.4.0
+    imagePullPolicy: IfNotPresent
+    imagePullSecrets:
+    - name: internal
+    resources:
+      requests:
+        memory: 16Gi
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		d := c.Engine(a1.AIEngineNameVLLM)
+		Expect(d.ImageRepository).To(Equal(constants.ImageRepositoryVllm))
+		Expect(d.ImageTag).To(Equal("v0.4.0"))
+		Expect(d.ImagePullPolicy).To(Equal(v1.PullIfNotPresent))
+		Expect(d.ImagePullSecrets).To(ConsistOf(v1.LocalObjectReference{Name: "internal"}))
+		Expect(d.Resources.Requests[v1.ResourceMemory]).To(Equal(resource.MustParse("16Gi")))
+
+		d.ImagePullSecrets[0].Name = "changed"
+		Expect(c.Engine(a1.AIEngineNameVLLM).ImagePullSecrets[0].Name).To(Equal("internal"))
+	})
+
+	It("rejects unknown fields", func() {
+		_, err := config.Parse([]byte("engines:\n  vllm:\n    image: foo\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects invalid values", func() {
+		_, err := config.Parse([]byte("engines:\n  vlm:\n    imageTag: v1\n"))
+		Expect(err).To(MatchError(ContainSubstring("unknown engine vlm")))
+
+		_, err = config.Parse([]byte("engines:\n  vllm:\n    imagePullPolicy: Sometimes\n"))
+		Expect(err).To(MatchError(ContainSubstring("unknown imagePullPolicy")))
+	})
+
+	It("treats a missing file as an empty config", func() {
+		config.Set(&config.Config{Engines: map[a1.AIEngineName]config.EngineDefaults{
+			a1.AIEngineNameVLLM: {ImageTag: "old"},
+		}})
+
+		Expect(config.Load(filepath.Join(GinkgoT().TempDir(), "config.yaml"))).To(Succeed())
+		Expect(config.Get().Engine(a1.AIEngineNameVLLM).ImageTag).To(Equal(constants.ImageTagLatest))
+	})
+
+	It("reloads the config when the file changes", func(ctx SpecContext) {
+		path := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(path, []byte("engines: {}\n"), 0o644)).To(Succeed())
+		Expect(config.Load(path)).To(Succeed())
+
+		w := config.NewWatcher(path)
+		w.Debounce = 100 * time.Millisecond
+		wctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(w.Start(wctx)).To(Succeed())
+		}()
+
+		// Give the watcher time to add the directory
+		time.Sleep(100 * time.Millisecond)
+
+		Expect(os.WriteFile(path, []byte("engines: {vllm: {imageTag: v1}}\n"), 0o644)).To(Succeed())
+		Eventually(w.Changes).WithTimeout(5 * time.Second).Should(Receive())
+		Expect(config.Get().Engine(a1.AIEngineNameVLLM).ImageTag).To(Equal("v1"))
+
+		By("keeping the previous config when the new one is invalid")
+		Expect(os.WriteFile(path, []byte("engines: [\n"), 0o644)).To(Succeed())
+		Consistently(w.Changes).WithTimeout(500 * time.Millisecond).ShouldNot(Receive())
+		Expect(config.Get().Engine(a1.AIEngineNameVLLM).ImageTag).To(Equal("v1"))
+
+		By("keeping the previous config when the file is emptied")
+		Expect(os.WriteFile(path, nil, 0o644)).To(Succeed())
+		Consistently(w.Changes).WithTimeout(500 * time.Millisecond).ShouldNot(Receive())
+		Expect(config.Get().Engine(a1.AIEngineNameVLLM).ImageTag).To(Equal("v1"))
+
+		By("reading the file once it has been written")
+		f, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.WriteString("engines:\n")
+		Expect(err).NotTo(HaveOccurred())
+		time.Sleep(20 * time.Millisecond)
+		_, err = f.WriteString("  vllm: {imageTag: v2}\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+		Eventually(w.Changes).WithTimeout(5 * time.Second).Should(Receive())
+		Expect(config.Get().Engine(a1.AIEngineNameVLLM).ImageTag).To(Equal("v2"))
+		Consistently(w.Changes).WithTimeout(300 * time.Millisecond).ShouldNot(Receive())
+	})
+
+	Describe("MirrorImage", func() {
+		c := &config.Config{RegistryMirrors: map[string]string{
+			"nvcr.io":        "registry.internal/nvcr",
+			"docker.io":      "registry.internal/dockerhub/",
+			"localhost:5000": "registry.internal/local",
+		}}
+
+		DescribeTable("rewrites the registry",
+			func(image, expected string) {
+				Expect(c.MirrorImage(image)).To(Equal(expected))
+			},
+			Entry("with a registry", "nvcr.io/nvidia/tritonserver:24.01-py3", "registry.internal/nvcr/nvidia/tritonserver:24.01-py3"),
+			Entry("on docker hub", "vllm/vllm-openai:latest", "registry.internal/dockerhub/vllm/vllm-openai:latest"),
+			Entry("of an official image", "busybox", "registry.internal/dockerhub/library/busybox"),
+			Entry("with docker hub's full name", "index.docker.io/vllm/vllm-openai", "registry.internal/dockerhub/vllm/vllm-openai"),
+			Entry("with a port", "localhost:5000/foo@sha256:abc", "registry.internal/local/foo@sha256:abc"),
+			Entry("without a mirror", "quay.io/go-skynet/local-ai:latest", "quay.io/go-skynet/local-ai:latest"),
+		)
+	})
+
+	Describe("RewriteURL", func() {
+		c := &config.Config{URLRewrites: map[string]string{
+			"https://huggingface.co/":          "https://hf.internal/",
+			"https://huggingface.co/TheBloke/": "https://models.internal/thebloke/",
+			"https://github.com/":              "https://gh.internal/",
+		}}
+
+		DescribeTable("rewrites the longest prefix",
+			func(uri, expected string) {
+				Expect(c.RewriteURL(uri)).To(Equal(expected))
+			},
+			Entry("with one match", "https://huggingface.co/foo/bar.gguf", "https://hf.internal/foo/bar.gguf"),
+			Entry("with several matches", "https://huggingface.co/TheBloke/x.gguf", "https://models.internal/thebloke/x.gguf"),
+			Entry("without a match", "https://example.com/x.gguf", "https://example.com/x.gguf"),
+			Entry("which isn't http", "huggingface.co/foo", "huggingface.co/foo"),
+		)
+	})
+})
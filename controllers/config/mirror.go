@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/premAI-io/prem-operator/pkg/registry"
+)
+
+// MirrorImage returns the image pulled from the mirror configured for its
+// registry, or the image unchanged if there is no mirror
+func (c *Config) MirrorImage(image string) string {
+	if len(c.RegistryMirrors) == 0 || image == "" {
+		return image
+	}
+
+	ref := registry.ParseReference(image)
+	mirror, ok := c.RegistryMirrors[ref.Registry]
+	if !ok {
+		return image
+	}
+
+	ref.Registry = strings.TrimSuffix(mirror, "/")
+	return ref.String()
+}
+
+// RewriteURL returns uri with the longest matching prefix in URLRewrites
+// replaced. Only http(s) URIs are rewritten.
+func (c *Config) RewriteURL(uri string) string {
+	if !strings.HasPrefix(uri, "http://") && !strings.HasPrefix(uri, "https://") {
+		return uri
+	}
+
+	match := ""
+	for from := range c.URLRewrites {
+		if strings.HasPrefix(uri, from) && len(from) > len(match) {
+			match = from
+		}
+	}
+
+	if match == "" {
+		return uri
+	}
+
+	return c.URLRewrites[match] + strings.TrimPrefix(uri, match)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Watcher reloads the config when its file changes. It watches the
+// directory rather than the file because ConfigMap volumes are updated by
+// swapping a symlink.
+type Watcher struct {
+	Path string
+
+	// Changes receives an event when a new config is loaded, it can be used
+	// to requeue the objects which depend on the config. If an event is
+	// already pending then no more are sent.
+	Changes chan event.GenericEvent
+
+	// Debounce is how long the file must be left alone before it is read,
+	// so that a file which is still being written isn't loaded
+	Debounce time.Duration
+
+	last []byte
+}
+
+func NewWatcher(path string) *Watcher {
+	data, _ := os.ReadFile(path)
+
+	return &Watcher{
+		Path:     path,
+		Changes:  make(chan event.GenericEvent, 1),
+		Debounce: time.Second,
+		last:     data,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every
+// replica needs the current config
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (w *Watcher) Start(ctx context.Context) error {
+	lg := log.FromContext(ctx).WithName("config-watcher")
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	if err := fw.Add(filepath.Dir(w.Path)); err != nil {
+		return err
+	}
+
+	// Each event restarts the timer, the file is read once it stops changing
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-fw.Errors:
+			lg.Error(err, "Error watching operator config")
+		case <-fw.Events:
+			settled = time.After(w.Debounce)
+		case <-settled:
+			settled = nil
+			w.reload(ctx)
+		}
+	}
+}
+
+func (w *Watcher) reload(ctx context.Context) {
+	lg := log.FromContext(ctx).WithName("config-watcher")
+
+	data, err := os.ReadFile(w.Path)
+	if err != nil && !os.IsNotExist(err) {
+		lg.Error(err, "Failed to read operator config, keeping the previous one", "path", w.Path)
+		return
+	}
+
+	if bytes.Equal(data, w.last) {
+		return
+	}
+
+	// A file which is truncated, half written or removed would otherwise
+	// replace the config with the built in defaults for every AIDeployment
+	if len(bytes.TrimSpace(data)) == 0 && len(bytes.TrimSpace(w.last)) > 0 {
+		lg.Info("Operator config is empty, keeping the previous one", "path", w.Path)
+		return
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		lg.Error(err, "Failed to parse operator config, keeping the previous one", "path", w.Path)
+		return
+	}
+
+	w.last = data
+	Set(c)
+	lg.Info("Loaded operator config", "path", w.Path)
+
+	select {
+	case w.Changes <- event.GenericEvent{}:
+	default:
+	}
+}
@@ -2,4 +2,8 @@ package constants
 
 const (
 	ContainerEngineName = "serving"
+	// Engines which serve several models with a container for each name
+	// them with this prefix and put a router in front of them
+	ContainerModelPrefix = ContainerEngineName + "-"
+	ContainerRouterName  = "router"
 )
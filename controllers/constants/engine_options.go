@@ -10,7 +10,35 @@ const (
 	ImageRepositoryDeepSpeedMii = "premai/deepspeed-mii"
 	ImageRepositoryTriton       = "nvcr.io/nvidia/tritonserver"
 	ImageTagTritonDefault       = "24.01-py3"
+	ImageRepositoryTGI          = "ghcr.io/huggingface/text-generation-inference"
+	ImageRepositoryOllama       = "ollama/ollama"
+	ImageRepositoryLlamaCpp     = "ghcr.io/ggerganov/llama.cpp"
+	ImageTagLlamaCppCPU         = "server"
+	ImageTagLlamaCppCUDA        = "server-cuda"
+	ImageRepositorySGLang       = "lmsysorg/sglang"
+	ImageRepositoryTEI          = "ghcr.io/huggingface/text-embeddings-inference"
+	ImageTagTEICPU              = "cpu-latest"
+	ImageTagTEICUDA             = "latest"
+	ImageRepositoryWhisper      = "ghcr.io/ggerganov/whisper.cpp"
+	ImageTagWhisperCPU          = "main"
+	ImageTagWhisperCUDA         = "main-cuda"
+	// Used to download models for engines whose image doesn't have curl
+	ImageCurl = "curlimages/curl:latest"
 
 	DtypeKey        = "dtype"
 	QuantizationKey = "quantization"
+	CtxSizeKey      = "ctxSize"
+	ThreadsKey      = "threads"
+	GPULayersKey    = "gpuLayers"
+	MaxTokensKey    = "maxTokens"
+	// The number of GPUs a model is split across
+	TensorParallelSizeKey = "tensorParallelSize"
+)
+
+const (
+	// The Triton image with the TensorRT-LLM backend and the image with the
+	// ensemble repository and OpenAI frontend which match it, built from
+	// containers/triton-trtllm-repository
+	ImageTagTritonTRTLLM        = "24.12-trtllm-python-py3"
+	ImageTritonTRTLLMRepository = "premai/triton-trtllm-repository:0.16.0"
 )
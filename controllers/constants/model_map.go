@@ -5,6 +5,7 @@ type AIModelSpecFieldName string
 const (
 	AIModelMapSpecEngineConfig AIModelSpecFieldName = "engineConfigFile"
 	AIModelMapSpecModelFiles   AIModelSpecFieldName = "modelFiles"
+	AIModelMapSpecChatTemplate AIModelSpecFieldName = "chatTemplate"
 
 	AIModelMapDefaultAnnotationKey = "mlcontroller.premlabs.io/aimodelmap"
 	AIModelMapDefaultLabelKey      = "mlcontroller.premlabs.io/aimodelmap"
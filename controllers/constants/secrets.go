@@ -1,5 +0,0 @@
-package constants
-
-const (
-	RegcredDockerhub = "regcred-dockerhub"
-)
@@ -1,7 +1,10 @@
 package engines
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/premAI-io/prem-operator/controllers/aideployment"
 	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
@@ -12,13 +15,37 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
 )
 
 type DeepSpeedMii struct {
 	AIDeployment *a1.AIDeployment
-	model        aimodelmap.ResolvedModel
+	// each model is served by its own container when there are several
+	models []aimodelmap.ResolvedModel
+}
+
+const (
+	deepSpeedMiiDefaultPort int32 = 8080
+	// The first port of the gRPC servers, the model servers follow it
+	deepSpeedMiiGRPCPort int32 = 50050
+)
+
+func init() {
+	Register(Engine{
+		Name: a1.AIEngineNameDeepSpeedMii,
+		Options: []string{
+			constants.DtypeKey, constants.QuantizationKey, constants.TensorParallelSizeKey, constants.MaxTokensKey,
+		},
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.DeepSpeedMii
+		},
+		Validate: validateDeepSpeedMii,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewDeepSpeedMii(ai, m)
+		},
+	})
 }
 
 func NewDeepSpeedMii(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
@@ -26,15 +53,93 @@ func NewDeepSpeedMii(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (ai
 		return nil, ErrModelsNotSpecified
 	}
 
-	if len(models) > 1 {
-		return nil, ErrorOnlyOneModel
+	return &DeepSpeedMii{AIDeployment: ai, models: models}, nil
+}
+
+func validateDeepSpeedMii(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := validateMultipleModels(ai, models); err != nil {
+		return fmt.Errorf("deepspeed-mii: %w", err)
+	}
+
+	// The router finds a model's container by its deployment name
+	names := map[string]bool{}
+	for _, m := range models {
+		if names[miiDeploymentName(m)] {
+			return fmt.Errorf("deepspeed-mii: model %s is listed more than once", m.Spec.Uri)
+		}
+		names[miiDeploymentName(m)] = true
 	}
 
-	return &DeepSpeedMii{AIDeployment: ai, model: models[0]}, nil
+	for _, m := range models {
+		opts := miiOptions(ai, m)
+		if opts.Dtype != "" && !slices.Contains(miiDtypes, opts.Dtype) {
+			return fmt.Errorf("deepspeed-mii: dtype must be one of %s", strings.Join(miiDtypes, ", "))
+		}
+		if opts.Quantization != "" && !slices.Contains(miiQuantizations, opts.Quantization) {
+			return fmt.Errorf("deepspeed-mii: quantization must be one of %s", strings.Join(miiQuantizations, ", "))
+		}
+	}
+
+	if err := intOptions(ai, constants.MaxTokensKey); err != nil {
+		return fmt.Errorf("deepspeed-mii: %w", err)
+	}
+
+	return validateTensorParallelSize(ai)
+}
+
+// The values DeepSpeed-MII accepts, these match the enums of
+// a1.DeepSpeedMiiOptions
+var (
+	miiDtypes        = []string{"float16", "bfloat16"}
+	miiQuantizations = []string{"wf6af16"}
+)
+
+// miiOptions returns the typed options with the others filled in from the
+// engine options or else the model's spec
+func miiOptions(ai *a1.AIDeployment, model aimodelmap.ResolvedModel) a1.DeepSpeedMiiOptions {
+	var opts a1.DeepSpeedMiiOptions
+	if ai.Spec.Engine.DeepSpeedMii != nil {
+		opts = *ai.Spec.Engine.DeepSpeedMii
+	}
+
+	if opts.Dtype == "" {
+		opts.Dtype = modelOption(ai, constants.DtypeKey, string(model.Spec.DataType))
+	}
+	if opts.Quantization == "" {
+		opts.Quantization = modelOption(ai, constants.QuantizationKey, string(model.Spec.Quantization))
+	}
+
+	return opts
+}
+
+// miiArgs renders the options of a model's container, the GPUs are split
+// between the models' containers unless the tensor parallel size is set
+func miiArgs(opts *a1.DeepSpeedMiiOptions, ai *a1.AIDeployment, models int) []string {
+	var args []string
+	if opts.Dtype != "" {
+		args = append(args, "--dtype", opts.Dtype)
+	}
+	if opts.Quantization != "" {
+		args = append(args, "--quantization", opts.Quantization)
+	}
+
+	if opts.TensorParallelSize != nil {
+		args = append(args, "--tensor-parallel", fmt.Sprint(*opts.TensorParallelSize))
+	} else if tp := modelTensorParallelSize(ai, models); tp != "" {
+		args = append(args, "--tensor-parallel", tp)
+	}
+
+	if opts.MaxTokens != nil {
+		args = append(args, "--max-tokens", fmt.Sprint(*opts.MaxTokens))
+	} else if n := ai.Spec.Engine.Options[constants.MaxTokensKey]; n != "" {
+		args = append(args, "--max-tokens", n)
+	}
+
+	return args
 }
 
 func (l *DeepSpeedMii) Port() int32 {
-	return 8080
+	return deepSpeedMiiDefaultPort
 }
 
 func (l *DeepSpeedMii) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
@@ -44,15 +149,7 @@ func (l *DeepSpeedMii) Deployment(owner metav1.Object) (*appsv1.Deployment, erro
 		OwnerReferences: resources.GenOwner(owner),
 	}
 
-	imageTag := constants.ImageTagLatest
-	if l.AIDeployment.Spec.Engine.Options[constants.ImageTagKey] != "" {
-		imageTag = l.AIDeployment.Spec.Engine.Options[constants.ImageTagKey]
-	}
-
-	imageRepository := constants.ImageRepositoryDeepSpeedMii
-	if l.AIDeployment.Spec.Engine.Options[constants.ImageRepositoryKey] != "" {
-		imageRepository = l.AIDeployment.Spec.Engine.Options[constants.ImageRepositoryKey]
-	}
+	defaults := engineDefaults(l.AIDeployment)
 
 	deployment := appsv1.Deployment{}
 	if l.AIDeployment.Spec.Deployment.PodTemplate != nil {
@@ -64,35 +161,92 @@ func (l *DeepSpeedMii) Deployment(owner metav1.Object) (*appsv1.Deployment, erro
 	pod := &deployment.Spec.Template.Spec
 
 	if pod.ImagePullSecrets == nil {
-		pod.ImagePullSecrets = []v1.LocalObjectReference{
-			{Name: constants.RegcredDockerhub},
-		}
+		pod.ImagePullSecrets = imagePullSecrets(l.AIDeployment, defaults.ImagePullSecrets)
 	}
 
 	serviceAccount := false
 
-	image := fmt.Sprintf("%s:%s", imageRepository, imageTag)
+	image := engineImage(l.AIDeployment, defaults)
+
+	pod.AutomountServiceAccountToken = &serviceAccount
+
+	if len(l.models) == 1 {
+		pod.Containers = append(pod.Containers, l.modelContainer(
+			image, defaults.ImagePullPolicy, l.models[0], constants.ContainerEngineName, "default", l.Port(), deepSpeedMiiGRPCPort,
+		))
+	} else {
+		// Each model has its own container, deployment name and ports. The
+		// router presents them as one endpoint serving /mii/<deployment name>.
+		routes := map[string]int32{}
+		for i, m := range l.models {
+			port := modelPort(l.Port(), i)
+			grpcPort := deepSpeedMiiGRPCPort + 100*int32(i+1)
+			pod.Containers = append(pod.Containers, l.modelContainer(
+				image, defaults.ImagePullPolicy, m, modelContainerName(i), miiDeploymentName(m), port, grpcPort,
+			))
+			routes[miiDeploymentName(m)] = port
+		}
 
+		router, err := routerContainer(image, defaults.ImagePullPolicy, l.Port(), routes, "/healthz")
+		if err != nil {
+			return nil, err
+		}
+		pod.Containers = append(pod.Containers, router)
+	}
+
+	deploymentLabels := resources.GenDefaultLabels(l.AIDeployment.Name)
+	deployment.Spec.Template.Labels = utils.MergeMaps(
+		deploymentLabels,
+		deployment.Spec.Template.Labels,
+		l.AIDeployment.Spec.Deployment.Labels,
+	)
+
+	deployment.Spec.Template.Annotations = utils.MergeMaps(
+		deployment.Spec.Template.Annotations,
+		l.AIDeployment.Spec.Deployment.Annotations,
+	)
+
+	deployment.ObjectMeta = objMeta
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: deploymentLabels}
+
+	return &deployment, nil
+}
+
+// miiDeploymentName is the name a model is served under when there are
+// several, e.g. microsoft-phi-2 for microsoft/phi-2
+func miiDeploymentName(m aimodelmap.ResolvedModel) string {
+	return utils.ToHostName(strings.ReplaceAll(m.Spec.Uri, "/", "-"))
+}
+
+// modelContainer renders the container which serves the model with the
+// deployment name on the ports
+func (l *DeepSpeedMii) modelContainer(
+	image string,
+	pullPolicy v1.PullPolicy,
+	model aimodelmap.ResolvedModel,
+	name, deploymentName string,
+	port, grpcPort int32,
+) v1.Container {
 	backendProbeHandler := v1.ProbeHandler{
 		// This is infact a gRPC server for the backend so we could use a gRPC probe here
 		TCPSocket: &v1.TCPSocketAction{
-			Port: intstr.FromInt(int(50051)),
+			Port: intstr.FromInt(int(grpcPort + 1)),
 		},
 	}
 
 	httpProbeHandler := v1.ProbeHandler{
 		HTTPGet: &v1.HTTPGetAction{
 			Path: "/healthz",
-			Port: intstr.FromInt(int(l.Port())),
+			Port: intstr.FromInt(int(port)),
 		},
 	}
 
 	container := v1.Container{
-		ImagePullPolicy: v1.PullAlways,
-		Name:            constants.ContainerEngineName,
+		ImagePullPolicy: pullPolicy,
+		Name:            name,
 		Image:           image,
 		Args: []string{
-			"--uri", l.model.Spec.Uri,
+			"--uri", model.Spec.Uri,
 		},
 		StartupProbe: &v1.Probe{
 			InitialDelaySeconds: 30,
@@ -112,27 +266,20 @@ func (l *DeepSpeedMii) Deployment(owner metav1.Object) (*appsv1.Deployment, erro
 		},
 	}
 
+	if name != constants.ContainerEngineName {
+		container.Args = append(container.Args,
+			"--deployment-name", deploymentName,
+			"--port", fmt.Sprint(port),
+			"--grpc-port", fmt.Sprint(grpcPort),
+		)
+	}
+
+	opts := miiOptions(l.AIDeployment, model)
+	container.Args = append(container.Args, miiArgs(&opts, l.AIDeployment, len(l.models))...)
+
 	mergeProbe(l.AIDeployment.Spec.Deployment.StartupProbe, container.StartupProbe)
 	mergeProbe(l.AIDeployment.Spec.Deployment.ReadinessProbe, container.ReadinessProbe)
 	mergeProbe(l.AIDeployment.Spec.Deployment.LivenessProbe, container.LivenessProbe)
 
-	pod.AutomountServiceAccountToken = &serviceAccount
-
-	pod.Containers = append(pod.Containers, container)
-	deploymentLabels := resources.GenDefaultLabels(l.AIDeployment.Name)
-	deployment.Spec.Template.Labels = utils.MergeMaps(
-		deploymentLabels,
-		deployment.Spec.Template.Labels,
-		l.AIDeployment.Spec.Deployment.Labels,
-	)
-
-	deployment.Spec.Template.Annotations = utils.MergeMaps(
-		deployment.Spec.Template.Annotations,
-		l.AIDeployment.Spec.Deployment.Annotations,
-	)
-
-	deployment.ObjectMeta = objMeta
-	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: deploymentLabels}
-
-	return &deployment, nil
+	return container
 }
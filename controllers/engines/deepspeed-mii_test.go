@@ -0,0 +1,140 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/config"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("DeepSpeed-MII", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "mii", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameDeepSpeedMii},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{
+			{Name: "mii", HostName: "mii-model", Spec: a1.AIModelSpec{Uri: "microsoft/phi-2"}},
+		}
+	})
+
+	render := func(ctx SpecContext) v1.PodSpec {
+		e, err := engines.Lookup(a1.AIEngineNameDeepSpeedMii)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return d.Spec.Template.Spec
+	}
+
+	It("serves one model on the default ports", func(ctx SpecContext) {
+		pod := render(ctx)
+		Expect(pod.Containers).To(HaveLen(1))
+		Expect(pod.Containers[0].Name).To(Equal(constants.ContainerEngineName))
+		Expect(pod.Containers[0].Args).To(Equal([]string{"--uri", "microsoft/phi-2"}))
+		Expect(pod.Containers[0].StartupProbe.TCPSocket.Port.IntValue()).To(Equal(50051))
+	})
+
+	It("gives each model its own deployment name and ports", func(ctx SpecContext) {
+		// Inline models share a host name so the deployment name comes from the URI
+		models = append(models, aimodelmap.ResolvedModel{
+			Name: "mii", HostName: "mii-model", Spec: a1.AIModelSpec{Uri: "mistralai/Mistral-7B-v0.1"},
+		})
+
+		pod := render(ctx)
+		Expect(pod.Containers).To(HaveLen(3))
+		Expect(pod.Containers[1].Args).To(ContainElements(
+			"--deployment-name", "mistralai-mistral-7b-v0-1", "--port", "8082", "--grpc-port", "50250",
+		))
+		Expect(pod.Containers[1].StartupProbe.TCPSocket.Port.IntValue()).To(Equal(50251))
+		Expect(pod.Containers[2].Env).To(ContainElements(
+			v1.EnvVar{Name: "ROUTES", Value: `{"microsoft-phi-2":8081,"mistralai-mistral-7b-v0-1":8082}`},
+			v1.EnvVar{Name: "HEALTH_PATH", Value: "/healthz"},
+		))
+	})
+
+	validate := func() error {
+		e, err := engines.Lookup(a1.AIEngineNameDeepSpeedMii)
+		Expect(err).NotTo(HaveOccurred())
+		return e.Validate(ai, models)
+	}
+
+	It("passes the model's dtype and the options to the server", func(ctx SpecContext) {
+		models[0].Spec.DataType = a1.AIModelDataTypeBFloat16
+		ai.Spec.Engine.Options = map[string]string{constants.MaxTokensKey: "4096"}
+		tp := int32(2)
+		ai.Spec.Engine.DeepSpeedMii = &a1.DeepSpeedMiiOptions{
+			Quantization:       "wf6af16",
+			TensorParallelSize: &tp,
+		}
+
+		Expect(validate()).To(Succeed())
+		Expect(render(ctx).Containers[0].Args).To(Equal([]string{
+			"--uri", "microsoft/phi-2",
+			"--dtype", "bfloat16",
+			"--quantization", "wf6af16",
+			"--tensor-parallel", "2",
+			"--max-tokens", "4096",
+		}))
+	})
+
+	It("prefers the typed options", func(ctx SpecContext) {
+		models[0].Spec.DataType = a1.AIModelDataTypeBFloat16
+		ai.Spec.Engine.Options = map[string]string{constants.MaxTokensKey: "4096"}
+		maxTokens := int32(8192)
+		ai.Spec.Engine.DeepSpeedMii = &a1.DeepSpeedMiiOptions{
+			Dtype:     "float16",
+			MaxTokens: &maxTokens,
+		}
+
+		Expect(render(ctx).Containers[0].Args).To(ContainElements("float16", "8192"))
+	})
+
+	It("rejects what MII can't load", func() {
+		models[0].Spec.Quantization = a1.AIModelQuantizationAWQ
+		Expect(validate()).To(MatchError(ContainSubstring("quantization must be one of wf6af16")))
+
+		models[0].Spec.Quantization = ""
+		models[0].Spec.DataType = a1.AIModelDataTypeInt8
+		Expect(validate()).To(MatchError(ContainSubstring("dtype must be one of")))
+
+		models[0].Spec.DataType = ""
+		ai.Spec.Engine.Options = map[string]string{constants.MaxTokensKey: "lots"}
+		Expect(validate()).To(MatchError(ContainSubstring("maxTokens must be an integer")))
+	})
+
+	It("only uses the pull secrets it is given", func(ctx SpecContext) {
+		Expect(render(ctx).ImagePullSecrets).To(BeEmpty())
+
+		ai.Spec.Deployment.PodTemplate = &v1.PodTemplateSpec{
+			Spec: v1.PodSpec{ImagePullSecrets: []v1.LocalObjectReference{{Name: "dockerhub"}}},
+		}
+		Expect(render(ctx).ImagePullSecrets).To(ConsistOf(v1.LocalObjectReference{Name: "dockerhub"}))
+	})
+
+	It("prefers the deployment's pull secrets to the operator config's", func(ctx SpecContext) {
+		config.Set(&config.Config{Engines: map[a1.AIEngineName]config.EngineDefaults{
+			a1.AIEngineNameDeepSpeedMii: {ImagePullSecrets: []v1.LocalObjectReference{{Name: "regcred-dockerhub"}}},
+		}})
+		DeferCleanup(config.Set, &config.Config{})
+		Expect(render(ctx).ImagePullSecrets).To(ConsistOf(v1.LocalObjectReference{Name: "regcred-dockerhub"}))
+
+		ai.Spec.Deployment.ImagePullSecrets = []v1.LocalObjectReference{{Name: "team-registry"}}
+		Expect(render(ctx).ImagePullSecrets).To(ConsistOf(v1.LocalObjectReference{Name: "team-registry"}))
+	})
+})
@@ -0,0 +1,144 @@
+package engines_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+// fakeCurl copies $SOURCE to the file given with -o and counts its calls in
+// $CALLS. With $FAIL set it writes part of the file and fails.
+const fakeCurl = `#!/bin/sh
+echo >> "$CALLS"
+while [ $# -gt 0 ]; do
+  [ "$1" = "-o" ] && out=$2
+  shift
+done
+if [ -n "$FAIL" ]; then
+  echo partial > "$out"
+  exit 22
+fi
+cp "$SOURCE" "$out"
+`
+
+var _ = Describe("Model downloads", func() {
+	var (
+		dir string
+		env map[string]string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(dir, "bin"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "bin", "curl"), []byte(fakeCurl), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("weights"), 0o644)).To(Succeed())
+
+		env = map[string]string{
+			"PATH":   filepath.Join(dir, "bin") + ":" + os.Getenv("PATH"),
+			"CALLS":  filepath.Join(dir, "calls"),
+			"SOURCE": filepath.Join(dir, "model.gguf"),
+		}
+	})
+
+	// run runs an init container's command with its env, overridden by env
+	run := func(c v1.Container, overrides map[string]string) error {
+		Expect(c.Command).To(Equal([]string{"sh", "-c"}))
+
+		cmd := exec.Command("sh", append([]string{"-c"}, c.Args...)...)
+		for _, e := range c.Env {
+			cmd.Env = append(cmd.Env, e.Name+"="+e.Value)
+		}
+		for k, v := range env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		for k, v := range overrides {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+
+		out, err := cmd.CombinedOutput()
+		GinkgoWriter.Println(string(out))
+		return err
+	}
+
+	calls := func() int {
+		data, err := os.ReadFile(env["CALLS"])
+		if os.IsNotExist(err) {
+			return 0
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return strings.Count(string(data), "\n")
+	}
+
+	render := func(ctx SpecContext, ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) v1.PodSpec {
+		e, err := engines.Lookup(ai.Spec.Engine.Name)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return d.Spec.Template.Spec
+	}
+
+	It("downloads a model file once and never keeps a partial one", func(ctx SpecContext) {
+		pod := render(ctx, &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec:       a1.AIDeploymentSpec{Engine: a1.AIEngine{Name: a1.AIEngineNameLlamaCpp}},
+		}, []aimodelmap.ResolvedModel{{
+			Name: "llama", HostName: "llama", Spec: a1.AIModelSpec{Uri: "https://example.com/llama.gguf"},
+		}})
+		file := filepath.Join(dir, "models", "llama.gguf")
+		init := pod.InitContainers[0]
+
+		Expect(run(init, map[string]string{"MODEL_FILE": file, "FAIL": "1"})).NotTo(Succeed())
+		Expect(file).NotTo(BeAnExistingFile())
+
+		Expect(run(init, map[string]string{"MODEL_FILE": file})).To(Succeed())
+		Expect(os.ReadFile(file)).To(Equal([]byte("weights")))
+		Expect(calls()).To(Equal(2))
+
+		By("reusing the file when the pod restarts")
+		Expect(run(init, map[string]string{"MODEL_FILE": file})).To(Succeed())
+		Expect(calls()).To(Equal(2))
+	})
+
+	It("extracts a tarball once", func(ctx SpecContext) {
+		Expect(exec.Command("tar", "czf", filepath.Join(dir, "adapter.tar.gz"), "-C", dir, "model.gguf").Run()).To(Succeed())
+		env["SOURCE"] = filepath.Join(dir, "adapter.tar.gz")
+
+		pod := render(ctx, &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "mistral", Namespace: "default"},
+			Spec:       a1.AIDeploymentSpec{Engine: a1.AIEngine{Name: a1.AIEngineNameVLLM}},
+		}, []aimodelmap.ResolvedModel{{
+			Name: "mistral",
+			Spec: a1.AIModelSpec{
+				Uri:      "mistralai/Mistral-7B-v0.1",
+				Adapters: []a1.LoRAAdapter{{Name: "sql", Uri: "https://example.com/sql.tar.gz"}},
+			},
+		}})
+		adapter := filepath.Join(dir, "adapters", "sql")
+		var init v1.Container
+		for _, c := range pod.InitContainers {
+			if c.Name == "init-adapter-sql" {
+				init = c
+			}
+		}
+
+		Expect(run(init, map[string]string{"ADAPTER_DIR": adapter, "FAIL": "1"})).NotTo(Succeed())
+		Expect(run(init, map[string]string{"ADAPTER_DIR": adapter})).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(adapter, "model.gguf"))).To(Equal([]byte("weights")))
+
+		Expect(run(init, map[string]string{"ADAPTER_DIR": adapter})).To(Succeed())
+		Expect(calls()).To(Equal(2))
+	})
+})
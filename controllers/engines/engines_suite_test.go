@@ -0,0 +1,13 @@
+package engines_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEngines(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Engines Suite")
+}
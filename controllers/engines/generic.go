@@ -1,9 +1,11 @@
 package engines
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
 	"github.com/premAI-io/prem-operator/controllers/constants"
 	"github.com/premAI-io/prem-operator/pkg/utils"
 
@@ -12,12 +14,24 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Generic struct {
 	AIDeployment *a1.AIDeployment
 }
 
+const genericDefaultPort int32 = 8000
+
+func init() {
+	Register(Engine{
+		Name: a1.AIEngineNameGeneric,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, _ []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewGeneric(ai), nil
+		},
+	})
+}
+
 func NewGeneric(ai *a1.AIDeployment) aideployment.MLEngine {
 	return &Generic{AIDeployment: ai}
 
@@ -27,7 +41,7 @@ func (l *Generic) Port() int32 {
 	if len(l.AIDeployment.Spec.Endpoint) > 0 {
 		return l.AIDeployment.Spec.Endpoint[0].Port
 	} else {
-		return int32(8000)
+		return genericDefaultPort
 	}
 }
 
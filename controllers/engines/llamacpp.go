@@ -0,0 +1,177 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	llamaCppDefaultPort int32 = 8080
+	llamaCppModelsPath        = "/models"
+	// Offload every layer, llama.cpp caps this at the model's layer count
+	llamaCppAllLayers = "999"
+)
+
+func init() {
+	Register(Engine{
+		Name:    a1.AIEngineNameLlamaCpp,
+		Options: []string{constants.CtxSizeKey, constants.ThreadsKey, constants.GPULayersKey},
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.LlamaCpp
+		},
+		Validate: validateLlamaCpp,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewLlamaCpp(ai, m)
+		},
+	})
+}
+
+// LlamaCpp serves a GGUF model with llama.cpp's llama-server
+type LlamaCpp struct {
+	AIDeployment *a1.AIDeployment
+	model        aimodelmap.ResolvedModel
+}
+
+func NewLlamaCpp(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+	if err := requireSingleModel(ai, models); err != nil {
+		return nil, err
+	}
+
+	return &LlamaCpp{AIDeployment: ai, model: models[0]}, nil
+}
+
+func validateLlamaCpp(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := requireSingleModel(ai, models); err != nil {
+		return err
+	}
+
+	if !isHTTP(models[0].Spec.Uri) {
+		return fmt.Errorf("llamacpp: the model URI must be an http(s) URL of a GGUF file")
+	}
+
+	return intOptions(ai, constants.CtxSizeKey, constants.ThreadsKey, constants.GPULayersKey)
+}
+
+func (l *LlamaCpp) Port() int32 {
+	return llamaCppDefaultPort
+}
+
+// args returns llama-server's flags. The thread count defaults to the CPUs
+// the engine is given and every layer is offloaded when there is a GPU.
+func (l *LlamaCpp) args(modelFile string) []string {
+	var opts a1.LlamaCppOptions
+	if l.AIDeployment.Spec.Engine.LlamaCpp != nil {
+		opts = *l.AIDeployment.Spec.Engine.LlamaCpp
+	}
+
+	args := []string{
+		"--host", "0.0.0.0",
+		"--port", fmt.Sprint(l.Port()),
+		"--model", modelFile,
+	}
+
+	if ctx := typedOption(opts.CtxSize, l.AIDeployment, constants.CtxSizeKey); ctx != "" {
+		args = append(args, "--ctx-size", ctx)
+	}
+
+	threads := typedOption(opts.Threads, l.AIDeployment, constants.ThreadsKey)
+	if threads == "" {
+		if n := cpuThreads(l.AIDeployment.Spec.Deployment.Resources); n > 0 {
+			threads = fmt.Sprint(n)
+		}
+	}
+	if threads != "" {
+		args = append(args, "--threads", threads)
+	}
+
+	layers := typedOption(opts.GPULayers, l.AIDeployment, constants.GPULayersKey)
+	if layers == "" && usesCUDA(l.AIDeployment) {
+		layers = llamaCppAllLayers
+	}
+	if layers != "" {
+		args = append(args, "--n-gpu-layers", layers)
+	}
+
+	return args
+}
+
+// cpuThreads is the number of whole CPUs in the limits, or the requests if
+// there is no limit
+func cpuThreads(res v1.ResourceRequirements) int64 {
+	q, ok := res.Limits[v1.ResourceCPU]
+	if !ok {
+		q, ok = res.Requests[v1.ResourceCPU]
+	}
+	if !ok {
+		return 0
+	}
+
+	return max(1, q.MilliValue()/1000)
+}
+
+func (l *LlamaCpp) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	defaults := engineDefaults(l.AIDeployment)
+	if defaults.ImageTag == "" {
+		defaults.ImageTag = constants.ImageTagLlamaCppCPU
+		if usesCUDA(l.AIDeployment) {
+			defaults.ImageTag = constants.ImageTagLlamaCppCUDA
+		}
+	}
+
+	deployment := newDeployment(l.AIDeployment, defaults)
+	pod := &deployment.Spec.Template.Spec
+
+	modelsMount := v1.VolumeMount{
+		Name:      "models",
+		MountPath: llamaCppModelsPath,
+	}
+	modelFile := fmt.Sprintf("%s/%s.gguf", llamaCppModelsPath, l.model.HostName)
+
+	pod.InitContainers = append(pod.InitContainers, downloadContainer(
+		fmt.Sprintf("init-models-%s", l.AIDeployment.Name),
+		constants.ImageCurl,
+		defaults.ImagePullPolicy,
+		l.model.Spec.Uri,
+		modelFile,
+		modelsMount,
+	))
+
+	container := v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           engineImage(l.AIDeployment, defaults),
+		Env:             l.AIDeployment.Spec.Env,
+		Args:            l.args(modelFile),
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: l.Port(),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{modelsMount},
+	}
+
+	setProbes(l.AIDeployment, &container, httpGet("/health", l.Port()))
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "models",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+
+	pod.Containers = append(pod.Containers, container)
+	finishDeployment(l.AIDeployment, owner, deployment)
+
+	return deployment, nil
+}
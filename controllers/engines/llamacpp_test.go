@@ -0,0 +1,96 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("LlamaCpp", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameLlamaCpp},
+				Deployment: a1.Deployment{
+					Resources: v1.ResourceRequirements{
+						Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4500m")},
+					},
+				},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name:     "llama-3-8b",
+			HostName: "llama-3-8b-q8",
+			Spec:     a1.AIModelSpec{Uri: "https://huggingface.co/x/resolve/main/llama-3-8b.Q8_0.gguf"},
+		}}
+	})
+
+	render := func(ctx SpecContext) *v1.PodSpec {
+		e, err := engines.Lookup(a1.AIEngineNameLlamaCpp)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return &d.Spec.Template.Spec
+	}
+
+	It("downloads the model and runs on the CPU", func(ctx SpecContext) {
+		pod := render(ctx)
+
+		Expect(pod.InitContainers).To(HaveLen(1))
+		Expect(pod.InitContainers[0].Image).To(Equal(constants.ImageCurl))
+		Expect(pod.InitContainers[0].Env).To(ContainElement(v1.EnvVar{Name: "MODEL_FILE", Value: "/models/llama-3-8b-q8.gguf"}))
+
+		c := pod.Containers[0]
+		Expect(c.Image).To(Equal("ghcr.io/ggerganov/llama.cpp:server"))
+		Expect(c.Args).To(Equal([]string{
+			"--host", "0.0.0.0",
+			"--port", "8080",
+			"--model", "/models/llama-3-8b-q8.gguf",
+			"--threads", "4",
+		}))
+		Expect(c.LivenessProbe.HTTPGet.Path).To(Equal("/health"))
+	})
+
+	It("offloads to the GPU with CUDA", func(ctx SpecContext) {
+		ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+		ai.Spec.Engine.Options = map[string]string{constants.CtxSizeKey: "8192", constants.ThreadsKey: "2"}
+
+		c := render(ctx).Containers[0]
+		Expect(c.Image).To(Equal("ghcr.io/ggerganov/llama.cpp:server-cuda"))
+		Expect(c.Args).To(ContainElements("--ctx-size", "8192", "--threads", "2", "--n-gpu-layers", "999"))
+	})
+
+	It("prefers the typed options", func(ctx SpecContext) {
+		threads, layers := int32(8), int32(0)
+		ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+		ai.Spec.Engine.Options = map[string]string{constants.ThreadsKey: "2"}
+		ai.Spec.Engine.LlamaCpp = &a1.LlamaCppOptions{Threads: &threads, GPULayers: &layers}
+
+		Expect(render(ctx).Containers[0].Args).To(ContainElements("--threads", "8", "--n-gpu-layers", "0"))
+	})
+
+	It("rejects options which aren't numbers", func(ctx SpecContext) {
+		ai.Spec.Engine.Options = map[string]string{constants.GPULayersKey: "all"}
+
+		e, err := engines.Lookup(a1.AIEngineNameLlamaCpp)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = e.Create(ctx, nil, ai, models)
+		Expect(err).To(MatchError(ContainSubstring(constants.GPULayersKey)))
+	})
+})
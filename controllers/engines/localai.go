@@ -1,6 +1,7 @@
 package engines
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type LocalAI struct {
@@ -22,12 +24,82 @@ type LocalAI struct {
 	Models       []aimodelmap.ResolvedModel
 }
 
+const localaiDefaultPort int32 = 8080
+
+func init() {
+	Register(Engine{
+		Name: a1.AIEngineNameLocalai,
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.Localai
+		},
+		Validate: validateLocalAI,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewLocalAI(ai, m), nil
+		},
+	})
+}
+
 func NewLocalAI(ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) aideployment.MLEngine {
 	return &LocalAI{AIDeployment: ai, Models: m}
 
 }
+
+// validateLocalAI checks that the adapters can be put next to their models.
+// LocalAI only knows the file of a model which the operator downloads.
+func validateLocalAI(_ *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	for _, m := range models {
+		if len(m.Spec.Adapters) == 0 {
+			continue
+		}
+		if m.Spec.EngineConfigFile != "" || !isHTTP(m.Spec.Uri) {
+			return fmt.Errorf("localai: model %s must have an http(s) uri and no engine config to use adapters", m.Name)
+		}
+		for _, a := range m.Spec.Adapters {
+			if !isHTTP(a.Uri) {
+				return fmt.Errorf("localai: adapter %s must have an http(s) uri", a.Name)
+			}
+		}
+	}
+
+	if err := validateAdapters(models); err != nil {
+		return fmt.Errorf("localai: %w", err)
+	}
+
+	return nil
+}
+
+// localAIAdapter downloads an adapter and writes a model config which
+// applies it to the model's file, so the adapter is a model of its own
+func localAIAdapter(image string, pullPolicy v1.PullPolicy, model string, a a1.LoRAAdapter) v1.Container {
+	file := "lora-" + a.Name + ".gguf"
+	config := fmt.Sprintf("name: %s\nparameters:\n  model: %s\nlora_adapter: %s\n", a.Name, model, file)
+
+	return v1.Container{
+		ImagePullPolicy: pullPolicy,
+		Name:            "init-adapter-" + a.Name,
+		Image:           image,
+		Command:         []string{"sh", "-c"},
+		Args: []string{
+			downloadOnce("$ADAPTER_PATH", "/models/$ADAPTER_FILE") +
+				` && printf '%s' "$ADAPTER_CONFIG" > /models/$ADAPTER_NAME.yaml`,
+		},
+		Env: []v1.EnvVar{
+			{Name: "ADAPTER_NAME", Value: a.Name},
+			{Name: "ADAPTER_FILE", Value: file},
+			{Name: "ADAPTER_PATH", Value: a.Uri},
+			{Name: "ADAPTER_CONFIG", Value: config},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: "/models",
+			},
+		},
+	}
+}
+
 func (l *LocalAI) Port() int32 {
-	return 8080
+	return localaiDefaultPort
 }
 
 func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
@@ -37,15 +109,7 @@ func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 		OwnerReferences: resources.GenOwner(owner),
 	}
 
-	imageTag := constants.ImageTagLatest
-	if l.AIDeployment.Spec.Engine.Options[constants.ImageTagKey] != "" {
-		imageTag = l.AIDeployment.Spec.Engine.Options[constants.ImageTagKey]
-	}
-
-	imageRepository := constants.ImageRepositoryLocalai
-	if l.AIDeployment.Spec.Engine.Options[constants.ImageRepositoryKey] != "" {
-		imageRepository = l.AIDeployment.Spec.Engine.Options[constants.ImageRepositoryKey]
-	}
+	defaults := engineDefaults(l.AIDeployment)
 
 	deployment := appsv1.Deployment{}
 	if l.AIDeployment.Spec.Deployment.PodTemplate != nil {
@@ -56,12 +120,16 @@ func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 	deployment.Spec.Replicas = l.AIDeployment.Spec.Deployment.Replicas
 	pod := &deployment.Spec.Template.Spec
 
+	if pod.ImagePullSecrets == nil {
+		pod.ImagePullSecrets = imagePullSecrets(l.AIDeployment, defaults.ImagePullSecrets)
+	}
+
 	serviceAccount := false
 
 	v := l.AIDeployment.Spec.Env
 
 	v = append(v, v1.EnvVar{Name: "MODELS_PATH", Value: "/models"})
-	image := fmt.Sprintf("%s:%s", imageRepository, imageTag)
+	image := engineImage(l.AIDeployment, defaults)
 
 	healthProbeHandler := v1.ProbeHandler{
 		HTTPGet: &v1.HTTPGetAction{
@@ -70,7 +138,7 @@ func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 		},
 	}
 	expose := &v1.Container{
-		ImagePullPolicy: v1.PullAlways,
+		ImagePullPolicy: defaults.ImagePullPolicy,
 		Name:            constants.ContainerEngineName,
 		Image:           image,
 		Env:             v,
@@ -151,11 +219,11 @@ func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 
 		if strings.HasPrefix(m.Spec.Uri, "http") {
 			pod.InitContainers = append(pod.InitContainers, v1.Container{
-				ImagePullPolicy: v1.PullAlways,
+				ImagePullPolicy: defaults.ImagePullPolicy,
 				Name:            fmt.Sprintf("init-models-%s", l.AIDeployment.Name),
 				Image:           image,
 				Command:         []string{"sh", "-c"},
-				Args:            []string{"curl -L -v -o /models/$MODEL_NAME $MODEL_PATH"},
+				Args:            []string{downloadOnce("$MODEL_PATH", "/models/$MODEL_NAME")},
 				Env: []v1.EnvVar{
 					{Name: "MODEL_NAME", Value: m.Name},
 					{Name: "MODEL_PATH", Value: m.Spec.Uri},
@@ -167,6 +235,10 @@ func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 					},
 				},
 			})
+
+			for _, a := range m.Spec.Adapters {
+				pod.InitContainers = append(pod.InitContainers, localAIAdapter(image, defaults.ImagePullPolicy, m.Name, a))
+			}
 		} else {
 			// Pass models as args.
 			// LocalAI accepts both names and full URLs passed by as Args.
@@ -185,7 +257,7 @@ func (l *LocalAI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 		})
 
 		pod.InitContainers = append(pod.InitContainers, v1.Container{
-			ImagePullPolicy: v1.PullAlways,
+			ImagePullPolicy: defaults.ImagePullPolicy,
 			Name:            fmt.Sprintf("init-%s-%s", configSourceVolume, l.AIDeployment.Name),
 			Image:           image,
 			Command:         []string{"sh", "-c"},
@@ -0,0 +1,71 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("LocalAI", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameLocalai},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name: "llama",
+			Spec: a1.AIModelSpec{
+				Uri: "https://models.example.com/llama-3-8b.Q4_K_M.gguf",
+				Adapters: []a1.LoRAAdapter{
+					{Name: "sql", Uri: "https://models.example.com/sql-lora.gguf"},
+				},
+			},
+		}}
+	})
+
+	validate := func() error {
+		e, err := engines.Lookup(a1.AIEngineNameLocalai)
+		Expect(err).NotTo(HaveOccurred())
+		return e.Validate(ai, models)
+	}
+
+	It("makes each adapter a model applied to the base model's file", func(ctx SpecContext) {
+		e, err := engines.Lookup(a1.AIEngineNameLocalai)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := d.Spec.Template.Spec
+		Expect(pod.InitContainers).To(HaveLen(2))
+		adapter := pod.InitContainers[1]
+		Expect(adapter.Name).To(Equal("init-adapter-sql"))
+		Expect(adapter.Env).To(ContainElements(
+			v1.EnvVar{Name: "ADAPTER_PATH", Value: "https://models.example.com/sql-lora.gguf"},
+			v1.EnvVar{Name: "ADAPTER_CONFIG", Value: "name: sql\nparameters:\n  model: llama\nlora_adapter: lora-sql.gguf\n"},
+		))
+	})
+
+	It("needs to download the base model to use adapters", func() {
+		models[0].Spec.Uri = "llama-3-8b-instruct"
+		Expect(validate()).To(MatchError(ContainSubstring("http(s) uri")))
+	})
+
+	It("needs the adapters to be downloadable", func() {
+		models[0].Spec.Adapters[0].Uri = "acme/sql-lora"
+		Expect(validate()).To(MatchError(ContainSubstring("adapter sql")))
+	})
+})
@@ -0,0 +1,136 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	ollamaDefaultPort      int32 = 11434
+	ollamaContainerVolPath       = "/root/.ollama"
+)
+
+// Model names in the Ollama library, e.g. llama3:8b or a registry path
+// such as registry.ollama.ai/library/llama3:latest
+var ollamaModelName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._:/-]*$`)
+
+// ollamaPullScript starts a server which is only reachable inside the pod,
+// pulls the models into the volume and stops
+const ollamaPullScript = `ollama serve & pid=$!
+until ollama list > /dev/null 2>&1; do sleep 1; done
+for m in $OLLAMA_PULL; do ollama pull "$m" || exit 1; done
+kill $pid`
+
+func init() {
+	Register(Engine{
+		Name: a1.AIEngineNameOllama,
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.Ollama
+		},
+		Validate: validateOllama,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewOllama(ai, m), nil
+		},
+	})
+}
+
+type Ollama struct {
+	AIDeployment *a1.AIDeployment
+	Models       []aimodelmap.ResolvedModel
+}
+
+func NewOllama(ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) aideployment.MLEngine {
+	return &Ollama{AIDeployment: ai, Models: m}
+}
+
+func validateOllama(_ *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if len(models) == 0 {
+		return ErrModelsNotSpecified
+	}
+
+	for _, m := range models {
+		if !ollamaModelName.MatchString(m.Spec.Uri) {
+			return fmt.Errorf("ollama: %q is not an Ollama model name", m.Spec.Uri)
+		}
+	}
+
+	return nil
+}
+
+func (o *Ollama) Port() int32 {
+	return ollamaDefaultPort
+}
+
+func (o *Ollama) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	defaults := engineDefaults(o.AIDeployment)
+	deployment := newDeployment(o.AIDeployment, defaults)
+	pod := &deployment.Spec.Template.Spec
+	image := engineImage(o.AIDeployment, defaults)
+
+	modelsMount := v1.VolumeMount{
+		Name:      "models",
+		MountPath: ollamaContainerVolPath,
+	}
+
+	names := make([]string, 0, len(o.Models))
+	for _, m := range o.Models {
+		names = append(names, m.Spec.Uri)
+	}
+
+	// The models are pulled before the engine starts so that it is only
+	// ready once they can be used
+	pod.InitContainers = append(pod.InitContainers, v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            fmt.Sprintf("init-models-%s", o.AIDeployment.Name),
+		Image:           image,
+		Command:         []string{"sh", "-c"},
+		Args:            []string{ollamaPullScript},
+		Env: []v1.EnvVar{
+			{Name: "OLLAMA_HOST", Value: "127.0.0.1:11434"},
+			{Name: "OLLAMA_PULL", Value: strings.Join(names, " ")},
+		},
+		VolumeMounts: []v1.VolumeMount{modelsMount},
+	})
+
+	container := v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           image,
+		Env: append([]v1.EnvVar{
+			{Name: "OLLAMA_HOST", Value: fmt.Sprintf("0.0.0.0:%d", o.Port())},
+		}, o.AIDeployment.Spec.Env...),
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: o.Port(),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{modelsMount},
+	}
+
+	setProbes(o.AIDeployment, &container, httpGet("/api/tags", o.Port()))
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "models",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+
+	pod.Containers = append(pod.Containers, container)
+	finishDeployment(o.AIDeployment, owner, deployment)
+
+	return deployment, nil
+}
@@ -0,0 +1,75 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("Ollama", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameOllama},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{
+			{Name: "llama3", Spec: a1.AIModelSpec{Uri: "llama3:8b"}},
+			{Name: "phi3", Spec: a1.AIModelSpec{Uri: "phi3:mini"}},
+		}
+	})
+
+	It("pulls the models into the volume before starting", func(ctx SpecContext) {
+		e, err := engines.Lookup(a1.AIEngineNameOllama)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := d.Spec.Template.Spec
+		Expect(pod.InitContainers).To(HaveLen(1))
+		Expect(pod.InitContainers[0].Env).To(ContainElement(v1.EnvVar{Name: "OLLAMA_PULL", Value: "llama3:8b phi3:mini"}))
+		Expect(pod.InitContainers[0].VolumeMounts).To(Equal(pod.Containers[0].VolumeMounts))
+
+		c := pod.Containers[0]
+		Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "OLLAMA_HOST", Value: "0.0.0.0:11434"}))
+		Expect(c.ReadinessProbe.HTTPGet.Path).To(Equal("/api/tags"))
+		Expect(c.VolumeMounts[0].MountPath).To(Equal("/root/.ollama"))
+	})
+
+	It("uses the image from the engine spec", func(ctx SpecContext) {
+		ai.Spec.Engine.Options = map[string]string{constants.ImageTagKey: "0.1.0"}
+		ai.Spec.Engine.Image = &a1.EngineImage{Tag: "0.3.0"}
+
+		e, err := engines.Lookup(a1.AIEngineNameOllama)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal(constants.ImageRepositoryOllama + ":0.3.0"))
+	})
+
+	It("rejects names which aren't Ollama models", func(ctx SpecContext) {
+		models[1].Spec.Uri = "phi3; rm -rf /"
+
+		e, err := engines.Lookup(a1.AIEngineNameOllama)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = e.Create(ctx, nil, ai, models)
+		Expect(err).To(MatchError(ContainSubstring("not an Ollama model")))
+	})
+})
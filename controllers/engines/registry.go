@@ -0,0 +1,141 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+// Engine is what each engine registers so that the controllers can use it
+// without a case for every engine
+type Engine struct {
+	Name a1.AIEngineName
+	// The keys of AIEngine.Options the engine reads, besides the image
+	// repository and tag which every engine reads
+	Options []string
+	// The engine passes every option on, so none of them are unknown
+	AnyOptions bool
+	// Returns the engine's variants in an AIModelMap, nil if the engine
+	// can't be used with AIModelMaps
+	ModelMapVariants aimodelmap.VariantsFunc
+	// Checks the AIDeployment and its models before the engine is created,
+	// it may be nil
+	Validate func(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error
+	// Creates the engine, the client is only needed by engines which read
+	// other objects
+	New func(
+		ctx context.Context,
+		c ctrlClient.Client,
+		ai *a1.AIDeployment,
+		models []aimodelmap.ResolvedModel,
+	) (aideployment.MLEngine, error)
+}
+
+var registry = map[a1.AIEngineName]*Engine{}
+
+// Register adds an engine to the registry, each engine calls it from init
+func Register(e Engine) {
+	if _, ok := registry[e.Name]; ok {
+		panic(fmt.Sprintf("engine %s is registered twice", e.Name))
+	}
+
+	registry[e.Name] = &e
+}
+
+// Lookup returns the registered engine with the name
+func Lookup(name a1.AIEngineName) (*Engine, error) {
+	e, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine %s", name)
+	}
+
+	return e, nil
+}
+
+// All returns the registered engines ordered by name
+func All() []*Engine {
+	all := make([]*Engine, 0, len(registry))
+	for _, e := range registry {
+		all = append(all, e)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	return all
+}
+
+// Create validates the AIDeployment and creates the engine for it
+func (e *Engine) Create(
+	ctx context.Context,
+	c ctrlClient.Client,
+	ai *a1.AIDeployment,
+	models []aimodelmap.ResolvedModel,
+) (aideployment.MLEngine, error) {
+	if e.Validate != nil {
+		if err := e.Validate(ai, models); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.New(ctx, c, ai, models)
+}
+
+// Warnings returns the problems with the AIDeployment which don't stop the
+// engine being created
+func (e *Engine) Warnings(ai *a1.AIDeployment) []string {
+	var warnings []string
+	for name, set := range typedOptions(&ai.Spec.Engine) {
+		if set && name != e.Name {
+			warnings = append(warnings, fmt.Sprintf("%s options are ignored by the %s engine", name, e.Name))
+		}
+	}
+
+	// The options of an AIEngineTemplate are whatever its template reads
+	if !e.AnyOptions {
+		for k := range ai.Spec.Engine.Options {
+			if k == constants.ImageRepositoryKey || k == constants.ImageTagKey || slices.Contains(e.Options, k) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("unknown %s engine option %s", e.Name, k))
+		}
+	}
+
+	sort.Strings(warnings)
+
+	return warnings
+}
+
+// typedOptions returns whether the typed options of each engine which has
+// them are set
+func typedOptions(engine *a1.AIEngine) map[a1.AIEngineName]bool {
+	return map[a1.AIEngineName]bool{
+		a1.AIEngineNameVLLM:         engine.VLLM != nil,
+		a1.AIEngineNameDeepSpeedMii: engine.DeepSpeedMii != nil,
+		a1.AIEngineNameTGI:          engine.TGI != nil,
+		a1.AIEngineNameSGLang:       engine.SGLang != nil,
+		a1.AIEngineNameLlamaCpp:     engine.LlamaCpp != nil,
+		a1.AIEngineNameTEI:          engine.TEI != nil,
+		a1.AIEngineNameWhisper:      engine.Whisper != nil,
+	}
+}
+
+// requireSingleModel is the validation for engines which serve one model
+func requireSingleModel(_ *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if len(models) == 0 {
+		return ErrModelsNotSpecified
+	}
+
+	if len(models) > 1 {
+		return ErrorOnlyOneModel
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("Registry", func() {
+	It("has every engine", func() {
+		names := []a1.AIEngineName{}
+		for _, e := range engines.All() {
+			names = append(names, e.Name)
+			Expect(e.New).NotTo(BeNil())
+		}
+
+		Expect(names).To(ContainElements(
+			a1.AIEngineNameDeepSpeedMii,
+			a1.AIEngineNameGeneric,
+			a1.AIEngineNameLlamaCpp,
+			a1.AIEngineNameLocalai,
+			a1.AIEngineNameOllama,
+			a1.AIEngineNameSGLang,
+			a1.AIEngineNameTEI,
+			a1.AIEngineNameTemplate,
+			a1.AIEngineNameTGI,
+			a1.AIEngineNameTriton,
+			a1.AIEngineNameVLLM,
+			a1.AIEngineNameWhisper,
+		))
+	})
+
+	It("rejects unknown engines", func() {
+		_, err := engines.Lookup("unknown")
+		Expect(err).To(MatchError(ContainSubstring("unknown engine")))
+	})
+
+	DescribeTable("finds the engine's AIModelMap variants",
+		func(name a1.AIEngineName, spec a1.AIModelMapSpec) {
+			e, err := engines.Lookup(name)
+			Expect(err).NotTo(HaveOccurred())
+
+			engine := &a1.AIEngine{Name: name, TemplateRef: &a1.AIEngineTemplateReference{Name: "tmpl"}}
+			Expect(e.ModelMapVariants(&spec, engine)).To(ConsistOf(HaveField("Variant", "v")))
+		},
+		Entry("localai", a1.AIEngineNameLocalai, a1.AIModelMapSpec{Localai: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("vllm", a1.AIEngineNameVLLM, a1.AIModelMapSpec{Vllm: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("deepspeed-mii", a1.AIEngineNameDeepSpeedMii, a1.AIModelMapSpec{DeepSpeedMii: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("triton", a1.AIEngineNameTriton, a1.AIModelMapSpec{TensorRT: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("tgi", a1.AIEngineNameTGI, a1.AIModelMapSpec{TGI: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("ollama", a1.AIEngineNameOllama, a1.AIModelMapSpec{Ollama: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("llamacpp", a1.AIEngineNameLlamaCpp, a1.AIModelMapSpec{LlamaCpp: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("sglang", a1.AIEngineNameSGLang, a1.AIModelMapSpec{SGLang: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("sglang using the vllm variants", a1.AIEngineNameSGLang, a1.AIModelMapSpec{Vllm: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("tei", a1.AIEngineNameTEI, a1.AIModelMapSpec{Embeddings: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("whisper", a1.AIEngineNameWhisper, a1.AIModelMapSpec{Whisper: []a1.AIModelVariant{{Variant: "v"}}}),
+		Entry("template", a1.AIEngineNameTemplate, a1.AIModelMapSpec{
+			Templates: map[string][]a1.AIModelVariant{"tmpl": {{Variant: "v"}}},
+		}),
+	)
+
+	It("validates the models before creating the engine", func(ctx SpecContext) {
+		e, err := engines.Lookup(a1.AIEngineNameVLLM)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = e.Create(ctx, nil, &a1.AIDeployment{}, nil)
+		Expect(err).To(MatchError(engines.ErrModelsNotSpecified))
+
+		// vLLM serves several models, TGI only one
+		e, err = engines.Lookup(a1.AIEngineNameTGI)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = e.Create(ctx, nil, &a1.AIDeployment{}, make([]aimodelmap.ResolvedModel, 2))
+		Expect(err).To(MatchError(engines.ErrorOnlyOneModel))
+	})
+
+	It("warns about engine options the engine doesn't read", func() {
+		ai := &a1.AIDeployment{Spec: a1.AIDeploymentSpec{Engine: a1.AIEngine{
+			Options: map[string]string{
+				constants.ImageTagKey: "v1",
+				constants.DtypeKey:    "float16",
+				"maxModelLen":         "4096",
+			},
+		}}}
+
+		e, err := engines.Lookup(a1.AIEngineNameVLLM)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Warnings(ai)).To(ConsistOf("unknown vllm engine option maxModelLen"))
+
+		e, err = engines.Lookup(a1.AIEngineNameTemplate)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Warnings(ai)).To(BeEmpty())
+	})
+
+	It("warns about typed options of another engine", func() {
+		ai := &a1.AIDeployment{Spec: a1.AIDeploymentSpec{Engine: a1.AIEngine{
+			VLLM: &a1.VLLMOptions{Dtype: "float16"},
+			TGI:  &a1.TGIOptions{Dtype: "float16"},
+		}}}
+
+		e, err := engines.Lookup(a1.AIEngineNameTGI)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Warnings(ai)).To(ConsistOf("vllm options are ignored by the tgi engine"))
+	})
+})
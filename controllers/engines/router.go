@@ -0,0 +1,186 @@
+package engines
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+// modelRouterScript sends each request to the container serving the model
+// named in the JSON body, as in the OpenAI API, or in a /mii/<name> path, as
+// in the DeepSpeed-MII API. It only uses the standard library so that it can
+// run in the engine's image.
+const modelRouterScript = `
+import http.client, json, os
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+
+ROUTES = json.loads(os.environ["ROUTES"])
+PORTS = sorted(set(ROUTES.values()))
+HEALTH_PATH = os.environ["HEALTH_PATH"]
+HOP = {"connection", "keep-alive", "transfer-encoding", "te", "trailer", "upgrade", "host", "content-length"}
+
+
+def fetch(port, path):
+    conn = http.client.HTTPConnection("127.0.0.1", port, timeout=5)
+    try:
+        conn.request("GET", path)
+        res = conn.getresponse()
+        return res.status, res.read()
+    except OSError:
+        return 503, b""
+    finally:
+        conn.close()
+
+
+class Router(BaseHTTPRequestHandler):
+    protocol_version = "HTTP/1.1"
+
+    def do_GET(self):
+        if self.path == "/health":
+            down = [p for p in PORTS if fetch(p, HEALTH_PATH)[0] != 200]
+            return self.reply(503 if down else 200, {"down": down})
+        if self.path == "/v1/models":
+            data = []
+            for p in PORTS:
+                status, body = fetch(p, "/v1/models")
+                if status == 200:
+                    data += json.loads(body).get("data", [])
+            return self.reply(200, {"object": "list", "data": data})
+        self.route(None)
+
+    def do_POST(self):
+        self.route(self.rfile.read(int(self.headers.get("Content-Length", 0))))
+
+    def route(self, body):
+        name = None
+        if self.path.startswith("/mii/"):
+            name = self.path.split("/")[2]
+        elif body:
+            try:
+                name = json.loads(body).get("model")
+            except (ValueError, AttributeError):
+                pass
+        if name not in ROUTES:
+            msg = "unknown model %r, the models are %s" % (name, ", ".join(sorted(ROUTES)))
+            return self.reply(404, {"error": {"message": msg, "type": "NotFoundError"}})
+
+        conn = http.client.HTTPConnection("127.0.0.1", ROUTES[name], timeout=600)
+        headers = {k: v for k, v in self.headers.items() if k.lower() not in HOP}
+        conn.request(self.command, self.path, body, headers)
+        res = conn.getresponse()
+        self.send_response(res.status)
+        for k, v in res.getheaders():
+            if k.lower() not in HOP:
+                self.send_header(k, v)
+        self.send_header("Transfer-Encoding", "chunked")
+        self.end_headers()
+        # Chunks are passed on as they arrive so that streamed responses work
+        while chunk := res.read1(65536):
+            self.wfile.write(b"%x\r\n%s\r\n" % (len(chunk), chunk))
+            self.wfile.flush()
+        self.wfile.write(b"0\r\n\r\n")
+        conn.close()
+
+    def reply(self, status, obj):
+        data = json.dumps(obj).encode()
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        self.send_header("Content-Length", str(len(data)))
+        self.end_headers()
+        self.wfile.write(data)
+
+
+ThreadingHTTPServer(("0.0.0.0", int(os.environ["PORT"])), Router).serve_forever()
+`
+
+// modelContainerName names the container of the i'th model so that
+// scheduling gives it a share of the resources
+func modelContainerName(i int) string {
+	return fmt.Sprintf("%s%d", constants.ContainerModelPrefix, i)
+}
+
+// modelPort is the port of the i'th model's container, the router has the
+// engine's port
+func modelPort(port int32, i int) int32 {
+	return port + 1 + int32(i)
+}
+
+// routerContainer returns the container which presents the model containers
+// as one endpoint. routes maps each model name to its container's port and
+// healthPath is where the model containers report that they are ready.
+func routerContainer(
+	image string,
+	pullPolicy v1.PullPolicy,
+	port int32,
+	routes map[string]int32,
+	healthPath string,
+) (v1.Container, error) {
+	r, err := json.Marshal(routes)
+	if err != nil {
+		return v1.Container{}, fmt.Errorf("failed to encode the model routes: %w", err)
+	}
+
+	health := httpGet("/health", port)
+
+	return v1.Container{
+		ImagePullPolicy: pullPolicy,
+		Name:            constants.ContainerRouterName,
+		Image:           image,
+		Command:         []string{"python3", "-c", modelRouterScript},
+		Env: []v1.EnvVar{
+			{Name: "PORT", Value: fmt.Sprint(port)},
+			{Name: "ROUTES", Value: string(r)},
+			{Name: "HEALTH_PATH", Value: healthPath},
+		},
+		Ports: []v1.ContainerPort{{ContainerPort: port, Protocol: v1.ProtocolTCP}},
+		ReadinessProbe: &v1.Probe{
+			FailureThreshold: 3,
+			ProbeHandler:     health,
+		},
+		LivenessProbe: &v1.Probe{
+			PeriodSeconds:    30,
+			TimeoutSeconds:   15,
+			FailureThreshold: 10,
+			ProbeHandler: v1.ProbeHandler{
+				TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(int(port))},
+			},
+		},
+	}, nil
+}
+
+// validateMultipleModels checks that an AIDeployment can run a container for
+// each of its models
+func validateMultipleModels(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if len(models) == 0 {
+		return ErrModelsNotSpecified
+	}
+
+	if len(models) == 1 {
+		return nil
+	}
+
+	if ai.Spec.Distributed != nil {
+		return fmt.Errorf("a distributed deployment can only have one model")
+	}
+
+	if ai.Spec.Workload == a1.WorkloadKServe {
+		return fmt.Errorf("the kserve workload can only have one model")
+	}
+
+	gpus, err := aideployment.GPUsPerPod(ai.Spec)
+	if err != nil {
+		return err
+	}
+	if gpus%int64(len(models)) != 0 {
+		return fmt.Errorf("%d GPUs can't be split evenly between %d models", gpus, len(models))
+	}
+
+	return nil
+}
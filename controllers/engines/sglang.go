@@ -0,0 +1,176 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	sglangDefaultPort      int32 = 30000
+	sglangContainerVolPath       = "/root/.cache/huggingface"
+)
+
+var (
+	// The values accepted by sglang.launch_server
+	sglangDataTypes     = []string{"auto", "half", "float16", "bfloat16", "float", "float32"}
+	sglangQuantizations = []string{
+		"awq", "awq_marlin", "fp8", "gptq", "gptq_marlin", "marlin",
+		"bitsandbytes", "gguf",
+	}
+)
+
+func init() {
+	Register(Engine{
+		Name:    a1.AIEngineNameSGLang,
+		Options: []string{constants.DtypeKey, constants.QuantizationKey, constants.TensorParallelSizeKey},
+		// Models which run on vLLM usually run on SGLang as well, so a
+		// deployment can switch engines without a new model map
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			if len(spec.SGLang) > 0 {
+				return spec.SGLang
+			}
+			return spec.Vllm
+		},
+		Validate: validateSGLang,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewSGLang(ai, m)
+		},
+	})
+}
+
+// SGLang is LMSYS's SGLang runtime with its OpenAI compatible server
+type SGLang struct {
+	AIDeployment *a1.AIDeployment
+	model        aimodelmap.ResolvedModel
+}
+
+func NewSGLang(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+	if err := requireSingleModel(ai, models); err != nil {
+		return nil, err
+	}
+
+	return &SGLang{AIDeployment: ai, model: models[0]}, nil
+}
+
+func validateSGLang(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := requireSingleModel(ai, models); err != nil {
+		return err
+	}
+
+	opts := sglangOptions(ai, models[0])
+	if opts.Dtype != "" && !slices.Contains(sglangDataTypes, opts.Dtype) {
+		return fmt.Errorf("sglang: dtype must be one of %s", strings.Join(sglangDataTypes, ", "))
+	}
+	if opts.Quantization != "" && !slices.Contains(sglangQuantizations, opts.Quantization) {
+		return fmt.Errorf("sglang: quantization must be one of %s", strings.Join(sglangQuantizations, ", "))
+	}
+
+	if len(models[0].Spec.Aliases) > 0 {
+		return fmt.Errorf("sglang: a model can only be served under one name, it can't have aliases")
+	}
+
+	if err := validateChatTemplates(ai, models); err != nil {
+		return fmt.Errorf("sglang: %w", err)
+	}
+
+	return validateTensorParallelSize(ai)
+}
+
+// sglangOptions returns the typed options with the others filled in from the
+// engine options or else the model's spec
+func sglangOptions(ai *a1.AIDeployment, model aimodelmap.ResolvedModel) a1.SGLangOptions {
+	var opts a1.SGLangOptions
+	if ai.Spec.Engine.SGLang != nil {
+		opts = *ai.Spec.Engine.SGLang
+	}
+
+	if opts.Dtype == "" {
+		opts.Dtype = modelOption(ai, constants.DtypeKey, string(model.Spec.DataType))
+	}
+	if opts.Quantization == "" {
+		opts.Quantization = modelOption(ai, constants.QuantizationKey, string(model.Spec.Quantization))
+	}
+
+	return opts
+}
+
+func (s *SGLang) Port() int32 {
+	return sglangDefaultPort
+}
+
+func (s *SGLang) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	defaults := engineDefaults(s.AIDeployment)
+	deployment := newDeployment(s.AIDeployment, defaults)
+	pod := &deployment.Spec.Template.Spec
+
+	container := v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           engineImage(s.AIDeployment, defaults),
+		Env:             s.AIDeployment.Spec.Env,
+		Command:         []string{"python3", "-m", "sglang.launch_server"},
+		Args: []string{
+			"--model-path", s.model.Spec.Uri,
+			"--host", "0.0.0.0",
+			"--port", fmt.Sprint(s.Port()),
+		},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: s.Port(),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: sglangContainerVolPath,
+			},
+		},
+	}
+
+	opts := sglangOptions(s.AIDeployment, s.model)
+	if opts.Dtype != "" {
+		container.Args = append(container.Args, "--dtype", opts.Dtype)
+	}
+	if opts.Quantization != "" {
+		container.Args = append(container.Args, "--quantization", opts.Quantization)
+	}
+	if tp := tensorParallelSize(opts.TensorParallelSize, s.AIDeployment); tp != "" {
+		container.Args = append(container.Args, "--tp-size", tp)
+	}
+
+	if name := s.model.Spec.ServedName; name != "" {
+		container.Args = append(container.Args, "--served-model-name", name)
+	}
+	if path := chatTemplatePath(&container, s.model); path != "" {
+		container.Args = append(container.Args, "--chat-template", path)
+	}
+
+	setProbes(s.AIDeployment, &container, httpGet("/health", s.Port()))
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "models",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+	addChatTemplates(a1.AIEngineNameSGLang, pod, []aimodelmap.ResolvedModel{s.model})
+	sharedMemory(pod, &container)
+
+	pod.Containers = append(pod.Containers, container)
+	finishDeployment(s.AIDeployment, owner, deployment)
+
+	return deployment, nil
+}
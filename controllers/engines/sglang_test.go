@@ -0,0 +1,110 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("SGLang", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameSGLang},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name: "llama",
+			Spec: a1.AIModelSpec{
+				Uri:      "meta-llama/Meta-Llama-3-8B-Instruct",
+				DataType: a1.AIModelDataTypeBFloat16,
+			},
+		}}
+	})
+
+	render := func(ctx SpecContext) v1.Container {
+		e, err := engines.Lookup(a1.AIEngineNameSGLang)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return d.Spec.Template.Spec.Containers[0]
+	}
+
+	It("passes the model path, dtype and port", func(ctx SpecContext) {
+		c := render(ctx)
+
+		Expect(c.Image).To(Equal(constants.ImageRepositorySGLang + ":latest"))
+		Expect(c.Command).To(Equal([]string{"python3", "-m", "sglang.launch_server"}))
+		Expect(c.Args).To(Equal([]string{
+			"--model-path", "meta-llama/Meta-Llama-3-8B-Instruct",
+			"--host", "0.0.0.0",
+			"--port", "30000",
+			"--dtype", "bfloat16",
+		}))
+		Expect(c.StartupProbe.HTTPGet.Path).To(Equal("/health"))
+	})
+
+	It("splits the model across the GPUs", func(ctx SpecContext) {
+		ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+		ai.Spec.Deployment.Resources.Requests = v1.ResourceList{
+			constants.NvidiaGPULabel: resource.MustParse("4"),
+		}
+		Expect(render(ctx).Args).To(ContainElements("--tp-size", "4"))
+
+		ai.Spec.Engine.Options = map[string]string{constants.TensorParallelSizeKey: "2"}
+		Expect(render(ctx).Args).To(ContainElements("--tp-size", "2"))
+
+		tp := int32(1)
+		ai.Spec.Engine.SGLang = &a1.SGLangOptions{TensorParallelSize: &tp}
+		Expect(render(ctx).Args).To(ContainElements("--tp-size", "1"))
+	})
+
+	It("serves the model under its served name with its chat template", func(ctx SpecContext) {
+		models[0].Namespace = "default"
+		models[0].Variant = "8b"
+		models[0].HostName = "llama-8b"
+		models[0].Spec.ServedName = "llama-3-8b"
+		models[0].Spec.ChatTemplate = "{{ messages }}"
+
+		c := render(ctx)
+		Expect(c.Args).To(ContainElements("--served-model-name", "llama-3-8b"))
+		Expect(c.Args).To(ContainElements("--chat-template", "/chat-templates/llama-8b.jinja"))
+	})
+
+	It("can't serve a model under several names", func() {
+		models[0].Spec.Aliases = []string{"llama"}
+
+		e, err := engines.Lookup(a1.AIEngineNameSGLang)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("aliases")))
+	})
+
+	DescribeTable("rejects options SGLang doesn't accept",
+		func(opts map[string]string) {
+			ai.Spec.Engine.Options = opts
+
+			e, err := engines.Lookup(a1.AIEngineNameSGLang)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(HaveOccurred())
+		},
+		Entry("an unsupported dtype", map[string]string{constants.DtypeKey: "int8"}),
+		Entry("an unsupported quantization", map[string]string{constants.QuantizationKey: "q4_0"}),
+		Entry("a zero tensor parallel size", map[string]string{constants.TensorParallelSizeKey: "0"}),
+	)
+})
@@ -0,0 +1,141 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	teiDefaultPort      int32 = 8080
+	teiContainerVolPath       = "/data"
+)
+
+// The values accepted by text-embeddings-router
+var teiDataTypes = []string{"float16", "float32"}
+
+func init() {
+	Register(Engine{
+		Name:    a1.AIEngineNameTEI,
+		Options: []string{constants.DtypeKey},
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.Embeddings
+		},
+		Validate: validateTEI,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewTEI(ai, m)
+		},
+	})
+}
+
+// TEI is Hugging Face's text-embeddings-inference. It serves embedding and
+// reranker models and has an OpenAI compatible /v1/embeddings route.
+type TEI struct {
+	AIDeployment *a1.AIDeployment
+	model        aimodelmap.ResolvedModel
+}
+
+func NewTEI(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+	if err := requireSingleModel(ai, models); err != nil {
+		return nil, err
+	}
+
+	return &TEI{AIDeployment: ai, model: models[0]}, nil
+}
+
+func validateTEI(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := requireSingleModel(ai, models); err != nil {
+		return err
+	}
+
+	dtype := teiDtype(ai, models[0])
+	if dtype != "" && !slices.Contains(teiDataTypes, dtype) {
+		return fmt.Errorf("tei: dtype must be one of %s", strings.Join(teiDataTypes, ", "))
+	}
+	// A quantization engine option is reported as an unknown option instead
+	if models[0].Spec.Quantization != "" {
+		return fmt.Errorf("tei: quantization isn't supported")
+	}
+
+	return nil
+}
+
+// teiDtype returns the typed dtype, or else the engine option or the model's
+// data type
+func teiDtype(ai *a1.AIDeployment, model aimodelmap.ResolvedModel) string {
+	if opts := ai.Spec.Engine.TEI; opts != nil && opts.Dtype != "" {
+		return opts.Dtype
+	}
+
+	return modelOption(ai, constants.DtypeKey, string(model.Spec.DataType))
+}
+
+func (t *TEI) Port() int32 {
+	return teiDefaultPort
+}
+
+func (t *TEI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	defaults := engineDefaults(t.AIDeployment)
+	if defaults.ImageTag == "" {
+		defaults.ImageTag = constants.ImageTagTEICPU
+		if usesCUDA(t.AIDeployment) {
+			defaults.ImageTag = constants.ImageTagTEICUDA
+		}
+	}
+
+	deployment := newDeployment(t.AIDeployment, defaults)
+	pod := &deployment.Spec.Template.Spec
+
+	container := v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           engineImage(t.AIDeployment, defaults),
+		Env:             t.AIDeployment.Spec.Env,
+		Args: []string{
+			"--model-id", t.model.Spec.Uri,
+			"--port", fmt.Sprint(t.Port()),
+			"--huggingface-hub-cache", teiContainerVolPath,
+		},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: t.Port(),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: teiContainerVolPath,
+			},
+		},
+	}
+
+	if dtype := teiDtype(t.AIDeployment, t.model); dtype != "" {
+		container.Args = append(container.Args, "--dtype", dtype)
+	}
+
+	setProbes(t.AIDeployment, &container, httpGet("/health", t.Port()))
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "models",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+
+	pod.Containers = append(pod.Containers, container)
+	finishDeployment(t.AIDeployment, owner, deployment)
+
+	return deployment, nil
+}
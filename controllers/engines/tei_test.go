@@ -0,0 +1,82 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("TEI", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "bge", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameTEI},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name: "bge",
+			Spec: a1.AIModelSpec{Uri: "BAAI/bge-large-en-v1.5"},
+		}}
+	})
+
+	render := func(ctx SpecContext) v1.Container {
+		e, err := engines.Lookup(a1.AIEngineNameTEI)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return d.Spec.Template.Spec.Containers[0]
+	}
+
+	It("uses the CPU image without an accelerator", func(ctx SpecContext) {
+		c := render(ctx)
+
+		Expect(c.Image).To(Equal(constants.ImageRepositoryTEI + ":cpu-latest"))
+		Expect(c.Args).To(Equal([]string{
+			"--model-id", "BAAI/bge-large-en-v1.5",
+			"--port", "8080",
+			"--huggingface-hub-cache", "/data",
+		}))
+		Expect(c.ReadinessProbe.HTTPGet.Path).To(Equal("/health"))
+	})
+
+	It("uses the GPU image with CUDA", func(ctx SpecContext) {
+		ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+		models[0].Spec.DataType = a1.AIModelDataTypeFloat16
+
+		c := render(ctx)
+		Expect(c.Image).To(Equal(constants.ImageRepositoryTEI + ":latest"))
+		Expect(c.Args).To(ContainElements("--dtype", "float16"))
+	})
+
+	It("rejects quantized models", func() {
+		models[0].Spec.Quantization = a1.AIModelQuantizationAWQ
+
+		e, err := engines.Lookup(a1.AIEngineNameTEI)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("quantization")))
+	})
+
+	It("warns about a quantization option", func() {
+		ai.Spec.Engine.Options = map[string]string{constants.QuantizationKey: "awq"}
+
+		e, err := engines.Lookup(a1.AIEngineNameTEI)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Validate(ai, models)).To(Succeed())
+		Expect(e.Warnings(ai)).To(ConsistOf("unknown tei engine option quantization"))
+	})
+})
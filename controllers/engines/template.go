@@ -0,0 +1,326 @@
+package engines
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+	"github.com/premAI-io/prem-operator/pkg/utils"
+)
+
+const (
+	defaultTemplateModelsPath       = "/models"
+	templateDefaultPort       int32 = 8000
+)
+
+func init() {
+	Register(Engine{
+		Name: a1.AIEngineNameTemplate,
+		// The options are passed on to the template
+		AnyOptions: true,
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, engine *a1.AIEngine) []a1.AIModelVariant {
+			if engine.TemplateRef == nil {
+				return nil
+			}
+			return spec.Templates[engine.TemplateRef.Name]
+		},
+		Validate: validateTemplateRef,
+		New: func(ctx context.Context, c ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			t, err := GetTemplate(ctx, c, ai)
+			if err != nil {
+				return nil, err
+			}
+			return NewTemplate(ai, t, m), nil
+		},
+	})
+}
+
+// Template renders an engine described by an AIEngineTemplate
+type Template struct {
+	AIDeployment *a1.AIDeployment
+	Template     *a1.AIEngineTemplate
+	Models       []aimodelmap.ResolvedModel
+}
+
+// TemplateModel is a model as seen by the template
+type TemplateModel struct {
+	aimodelmap.ResolvedModel
+	// Where the engine finds the model, the downloaded file if the template
+	// downloads models otherwise the model's URI
+	Path string
+}
+
+// TemplateData is what an AIEngineTemplate's command, args and env are
+// rendered with
+type TemplateData struct {
+	// The first model, it is empty if there are no models
+	Model  TemplateModel
+	Models []TemplateModel
+	Port   int32
+	// The AIDeployment's engine options
+	Options map[string]string
+}
+
+// validateTemplateRef checks that the AIDeployment references a template in
+// its own namespace. Templates set the image, command and env of the pod so
+// one namespace mustn't be able to run another's.
+func validateTemplateRef(ai *a1.AIDeployment, _ []aimodelmap.ResolvedModel) error {
+	ref := ai.Spec.Engine.TemplateRef
+	if ref == nil || ref.Name == "" {
+		return fmt.Errorf("deployment %s/%s uses the template engine without a templateRef", ai.Namespace, ai.Name)
+	}
+
+	if ref.Namespace != "" && ref.Namespace != ai.Namespace {
+		return fmt.Errorf(
+			"template: templateRef %s/%s must be in the AIDeployment's namespace %s",
+			ref.Namespace, ref.Name, ai.Namespace,
+		)
+	}
+
+	return nil
+}
+
+// GetTemplate fetches the AIEngineTemplate referenced by the AIDeployment
+// from the AIDeployment's namespace
+func GetTemplate(ctx context.Context, c ctrlClient.Client, ai *a1.AIDeployment) (*a1.AIEngineTemplate, error) {
+	if err := validateTemplateRef(ai, nil); err != nil {
+		return nil, err
+	}
+
+	t := &a1.AIEngineTemplate{}
+	key := ctrlClient.ObjectKey{Namespace: ai.Namespace, Name: ai.Spec.Engine.TemplateRef.Name}
+	if err := c.Get(ctx, key, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func NewTemplate(ai *a1.AIDeployment, t *a1.AIEngineTemplate, m []aimodelmap.ResolvedModel) aideployment.MLEngine {
+	return &Template{AIDeployment: ai, Template: t, Models: m}
+}
+
+func (t *Template) Port() int32 {
+	if t.Template.Spec.Port != 0 {
+		return t.Template.Spec.Port
+	}
+
+	return templateDefaultPort
+}
+
+func (t *Template) downloads(m aimodelmap.ResolvedModel) bool {
+	return t.Template.Spec.ModelDownload.Strategy == a1.ModelDownloadStrategyHTTP &&
+		strings.HasPrefix(m.Spec.Uri, "http")
+}
+
+func (t *Template) modelsPath() string {
+	if p := t.Template.Spec.ModelDownload.MountPath; p != "" {
+		return p
+	}
+
+	return defaultTemplateModelsPath
+}
+
+func (t *Template) data() TemplateData {
+	d := TemplateData{
+		Models:  make([]TemplateModel, 0, len(t.Models)),
+		Port:    t.Port(),
+		Options: t.AIDeployment.Spec.Engine.Options,
+	}
+
+	for _, m := range t.Models {
+		path := m.Spec.Uri
+		if t.downloads(m) {
+			path = t.modelsPath() + "/" + m.HostName
+		}
+		d.Models = append(d.Models, TemplateModel{ResolvedModel: m, Path: path})
+	}
+
+	if len(d.Models) > 0 {
+		d.Model = d.Models[0]
+	}
+
+	return d
+}
+
+// renderTemplate renders a single command, arg or env value
+func renderTemplate(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// renderAll renders a list of templates dropping the empty results
+func renderAll(field string, texts []string, data TemplateData) ([]string, error) {
+	out := make([]string, 0, len(texts))
+
+	for i, text := range texts {
+		s, err := renderTemplate(fmt.Sprintf("%s[%d]", field, i), text, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+
+	return out, nil
+}
+
+func (t *Template) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	objMeta := metav1.ObjectMeta{
+		Name:            t.AIDeployment.Name,
+		Namespace:       t.AIDeployment.Namespace,
+		OwnerReferences: resources.GenOwner(owner),
+	}
+
+	spec := &t.Template.Spec
+	data := t.data()
+
+	command, err := renderAll("command", spec.Command, data)
+	if err != nil {
+		return nil, fmt.Errorf("AIEngineTemplate %s: %w", t.Template.Name, err)
+	}
+
+	args, err := renderAll("args", spec.Args, data)
+	if err != nil {
+		return nil, fmt.Errorf("AIEngineTemplate %s: %w", t.Template.Name, err)
+	}
+
+	env := make([]v1.EnvVar, 0, len(spec.Env)+len(t.AIDeployment.Spec.Env))
+	for _, e := range spec.Env {
+		e := *e.DeepCopy()
+		if e.Value, err = renderTemplate("env."+e.Name, e.Value, data); err != nil {
+			return nil, fmt.Errorf("AIEngineTemplate %s: %w", t.Template.Name, err)
+		}
+		env = append(env, e)
+	}
+	env = append(env, t.AIDeployment.Spec.Env...)
+
+	deployment := appsv1.Deployment{}
+	if t.AIDeployment.Spec.Deployment.PodTemplate != nil {
+		deployment.Spec.Template = *t.AIDeployment.Spec.Deployment.PodTemplate.DeepCopy()
+	}
+	deployment.Spec.Replicas = t.AIDeployment.Spec.Deployment.Replicas
+	pod := &deployment.Spec.Template.Spec
+
+	if pod.ImagePullSecrets == nil {
+		pod.ImagePullSecrets = imagePullSecrets(t.AIDeployment, spec.ImagePullSecrets)
+	}
+
+	serviceAccount := false
+
+	image := spec.Image
+
+	pullPolicy := spec.ImagePullPolicy
+	if pullPolicy == "" {
+		pullPolicy = v1.PullIfNotPresent
+	}
+
+	tcpProbeHandler := v1.ProbeHandler{
+		TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(int(t.Port()))},
+	}
+
+	expose := &v1.Container{
+		ImagePullPolicy: pullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           image,
+		Command:         command,
+		Args:            args,
+		Env:             env,
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: t.Port(),
+			},
+		},
+		Resources:      *spec.Resources.DeepCopy(),
+		StartupProbe:   spec.StartupProbe.DeepCopy(),
+		ReadinessProbe: spec.ReadinessProbe.DeepCopy(),
+		LivenessProbe:  spec.LivenessProbe.DeepCopy(),
+	}
+
+	// Without probes the AIDeployment would be ready before the engine is
+	if expose.StartupProbe == nil {
+		expose.StartupProbe = &v1.Probe{PeriodSeconds: 10, FailureThreshold: 120, ProbeHandler: tcpProbeHandler}
+	}
+	if expose.ReadinessProbe == nil {
+		expose.ReadinessProbe = &v1.Probe{FailureThreshold: 3, ProbeHandler: tcpProbeHandler}
+	}
+	if expose.LivenessProbe == nil {
+		expose.LivenessProbe = &v1.Probe{PeriodSeconds: 30, FailureThreshold: 10, ProbeHandler: tcpProbeHandler}
+	}
+
+	mergeProbe(t.AIDeployment.Spec.Deployment.StartupProbe, expose.StartupProbe)
+	mergeProbe(t.AIDeployment.Spec.Deployment.ReadinessProbe, expose.ReadinessProbe)
+	mergeProbe(t.AIDeployment.Spec.Deployment.LivenessProbe, expose.LivenessProbe)
+
+	if spec.ModelDownload.Strategy == a1.ModelDownloadStrategyHTTP {
+		modelsMount := v1.VolumeMount{Name: "models", MountPath: t.modelsPath()}
+		expose.VolumeMounts = append(expose.VolumeMounts, modelsMount)
+
+		pod.Volumes = append(pod.Volumes, v1.Volume{
+			Name: "models",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		})
+
+		downloadImage := spec.ModelDownload.Image
+		if downloadImage == "" {
+			downloadImage = image
+		}
+
+		for _, m := range data.Models {
+			if !t.downloads(m.ResolvedModel) {
+				continue
+			}
+
+			pod.InitContainers = append(pod.InitContainers, downloadContainer(
+				fmt.Sprintf("init-%s", m.HostName), downloadImage, pullPolicy, m.Spec.Uri, m.Path, modelsMount,
+			))
+		}
+	}
+
+	pod.AutomountServiceAccountToken = &serviceAccount
+	pod.Containers = append(pod.Containers, *expose)
+
+	deploymentLabels := resources.GenDefaultLabels(t.AIDeployment.Name)
+	deployment.Spec.Template.Labels = utils.MergeMaps(
+		deploymentLabels,
+		deployment.Spec.Template.Labels,
+		t.AIDeployment.Spec.Deployment.Labels,
+	)
+
+	deployment.Spec.Template.Annotations = utils.MergeMaps(
+		deployment.Spec.Template.Annotations,
+		t.AIDeployment.Spec.Deployment.Annotations,
+	)
+
+	deployment.ObjectMeta = objMeta
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: deploymentLabels}
+
+	return &deployment, nil
+}
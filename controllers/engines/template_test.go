@@ -0,0 +1,127 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("Template", func() {
+	var (
+		ai     *a1.AIDeployment
+		tmpl   *a1.AIEngineTemplate
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "phi", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{
+					Name:        a1.AIEngineNameTemplate,
+					TemplateRef: &a1.AIEngineTemplateReference{Name: "llama-cpp"},
+					Options:     map[string]string{"ctxSize": "4096"},
+				},
+				Env: []v1.EnvVar{{Name: "DEBUG", Value: "1"}},
+			},
+		}
+		tmpl = &a1.AIEngineTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama-cpp", Namespace: "default"},
+			Spec: a1.AIEngineTemplateSpec{
+				Image: "llama.cpp:server",
+				Port:  8080,
+				Args: []string{
+					"--port={{ .Port }}",
+					"--model={{ .Model.Path }}",
+					"{{ with .Options.ctxSize }}--ctx-size={{ . }}{{ end }}",
+					"{{ with .Options.threads }}--threads={{ . }}{{ end }}",
+				},
+				Env: []v1.EnvVar{{Name: "MODEL_NAME", Value: "{{ .Model.Name }}-{{ .Model.Variant }}"}},
+				ModelDownload: a1.ModelDownload{
+					Strategy: a1.ModelDownloadStrategyHTTP,
+					Image:    "curlimages/curl",
+				},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name:     "phi-3",
+			Variant:  "q4",
+			HostName: "phi-3-q4",
+			Spec:     a1.AIModelSpec{Uri: "https://example.com/phi-3-q4.gguf"},
+		}}
+	})
+
+	It("renders the args and env from the models", func() {
+		d, err := engines.NewTemplate(ai, tmpl, models).Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		c := d.Spec.Template.Spec.Containers[0]
+		Expect(c.Name).To(Equal(constants.ContainerEngineName))
+		Expect(c.Image).To(Equal("llama.cpp:server"))
+		Expect(c.Args).To(Equal([]string{"--port=8080", "--model=/models/phi-3-q4", "--ctx-size=4096"}))
+		Expect(c.Env).To(Equal([]v1.EnvVar{
+			{Name: "MODEL_NAME", Value: "phi-3-q4"},
+			{Name: "DEBUG", Value: "1"},
+		}))
+		Expect(c.ReadinessProbe.TCPSocket.Port.IntValue()).To(Equal(8080))
+	})
+
+	It("downloads http models in an init container", func() {
+		d, err := engines.NewTemplate(ai, tmpl, models).Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := d.Spec.Template.Spec
+		Expect(pod.InitContainers).To(HaveLen(1))
+		Expect(pod.InitContainers[0].Image).To(Equal("curlimages/curl"))
+		Expect(pod.InitContainers[0].Env).To(ContainElement(v1.EnvVar{Name: "MODEL_FILE", Value: "/models/phi-3-q4"}))
+		Expect(pod.Containers[0].VolumeMounts).To(ContainElement(v1.VolumeMount{Name: "models", MountPath: "/models"}))
+	})
+
+	It("passes the URI when the engine fetches the model", func() {
+		tmpl.Spec.ModelDownload = a1.ModelDownload{}
+
+		d, err := engines.NewTemplate(ai, tmpl, models).Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.Spec.Template.Spec.InitContainers).To(BeEmpty())
+		Expect(d.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--model=https://example.com/phi-3-q4.gguf"))
+	})
+
+	It("merges the AIDeployment's probe settings", func() {
+		tmpl.Spec.ReadinessProbe = &v1.Probe{PeriodSeconds: 5}
+		ai.Spec.Deployment.ReadinessProbe = &a1.Probe{PeriodSeconds: 20}
+
+		d, err := engines.NewTemplate(ai, tmpl, models).Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Spec.Template.Spec.Containers[0].ReadinessProbe.PeriodSeconds).To(Equal(int32(20)))
+		Expect(tmpl.Spec.ReadinessProbe.PeriodSeconds).To(Equal(int32(5)))
+	})
+
+	It("reports invalid templates", func() {
+		tmpl.Spec.Args = []string{"--model={{ .Model.Path"}
+
+		_, err := engines.NewTemplate(ai, tmpl, models).Deployment(ai)
+		Expect(err).To(MatchError(ContainSubstring("llama-cpp")))
+	})
+	It("only uses templates from the AIDeployment's namespace", func(ctx SpecContext) {
+		e, err := engines.Lookup(a1.AIEngineNameTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		ai.Spec.Engine.TemplateRef.Namespace = "default"
+		Expect(e.Validate(ai, models)).To(Succeed())
+
+		ai.Spec.Engine.TemplateRef.Namespace = "other"
+		Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("must be in the AIDeployment's namespace default")))
+
+		// The template isn't fetched from the other namespace even if it exists there
+		tmpl.Namespace = "other"
+		_, err = engines.GetTemplate(ctx, nil, ai)
+		Expect(err).To(MatchError(ContainSubstring("must be in the AIDeployment's namespace")))
+	})
+})
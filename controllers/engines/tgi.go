@@ -0,0 +1,158 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	tgiDefaultPort      int32 = 8080
+	tgiContainerVolPath       = "/data"
+)
+
+var (
+	// The values accepted by text-generation-launcher
+	tgiDataTypes     = []string{"float16", "bfloat16"}
+	tgiQuantizations = []string{
+		"awq", "eetq", "exl2", "gptq", "marlin", "bitsandbytes",
+		"bitsandbytes-nf4", "bitsandbytes-fp4", "fp8",
+	}
+)
+
+func init() {
+	Register(Engine{
+		Name:    a1.AIEngineNameTGI,
+		Options: []string{constants.DtypeKey, constants.QuantizationKey, constants.TensorParallelSizeKey},
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.TGI
+		},
+		Validate: validateTGI,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewTGI(ai, m)
+		},
+	})
+}
+
+// TGI is Hugging Face's text-generation-inference
+type TGI struct {
+	AIDeployment *a1.AIDeployment
+	model        aimodelmap.ResolvedModel
+}
+
+func NewTGI(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+	if err := requireSingleModel(ai, models); err != nil {
+		return nil, err
+	}
+
+	return &TGI{AIDeployment: ai, model: models[0]}, nil
+}
+
+func validateTGI(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := requireSingleModel(ai, models); err != nil {
+		return err
+	}
+
+	opts := tgiOptions(ai, models[0])
+	if opts.Dtype != "" && !slices.Contains(tgiDataTypes, opts.Dtype) {
+		return fmt.Errorf("tgi: dtype must be one of %s", strings.Join(tgiDataTypes, ", "))
+	}
+	if opts.Quantization != "" && !slices.Contains(tgiQuantizations, opts.Quantization) {
+		return fmt.Errorf("tgi: quantization must be one of %s", strings.Join(tgiQuantizations, ", "))
+	}
+	if opts.Dtype != "" && opts.Quantization != "" {
+		return fmt.Errorf("tgi: dtype can't be used with quantization")
+	}
+
+	return validateTensorParallelSize(ai)
+}
+
+// tgiOptions returns the typed options with the others filled in from the
+// engine options or else the model's spec
+func tgiOptions(ai *a1.AIDeployment, model aimodelmap.ResolvedModel) a1.TGIOptions {
+	var opts a1.TGIOptions
+	if ai.Spec.Engine.TGI != nil {
+		opts = *ai.Spec.Engine.TGI
+	}
+
+	if opts.Dtype == "" {
+		opts.Dtype = modelOption(ai, constants.DtypeKey, string(model.Spec.DataType))
+	}
+	if opts.Quantization == "" {
+		opts.Quantization = modelOption(ai, constants.QuantizationKey, string(model.Spec.Quantization))
+	}
+
+	return opts
+}
+
+func (t *TGI) Port() int32 {
+	return tgiDefaultPort
+}
+
+func (t *TGI) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	defaults := engineDefaults(t.AIDeployment)
+	deployment := newDeployment(t.AIDeployment, defaults)
+	pod := &deployment.Spec.Template.Spec
+
+	container := v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           engineImage(t.AIDeployment, defaults),
+		Env: append([]v1.EnvVar{
+			{Name: "HUGGINGFACE_HUB_CACHE", Value: tgiContainerVolPath},
+		}, t.AIDeployment.Spec.Env...),
+		Args: []string{
+			"--model-id", t.model.Spec.Uri,
+			"--port", fmt.Sprint(t.Port()),
+		},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: t.Port(),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: tgiContainerVolPath,
+			},
+		},
+	}
+
+	opts := tgiOptions(t.AIDeployment, t.model)
+	if opts.Dtype != "" {
+		container.Args = append(container.Args, "--dtype", opts.Dtype)
+	}
+	if opts.Quantization != "" {
+		container.Args = append(container.Args, "--quantize", opts.Quantization)
+	}
+	if shards := tensorParallelSize(opts.TensorParallelSize, t.AIDeployment); shards != "" {
+		container.Args = append(container.Args, "--num-shard", shards)
+	}
+
+	setProbes(t.AIDeployment, &container, httpGet("/health", t.Port()))
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "models",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+	sharedMemory(pod, &container)
+
+	pod.Containers = append(pod.Containers, container)
+	finishDeployment(t.AIDeployment, owner, deployment)
+
+	return deployment, nil
+}
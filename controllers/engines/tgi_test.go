@@ -0,0 +1,123 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("TGI", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "mistral", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameTGI},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name: "mistral",
+			Spec: a1.AIModelSpec{
+				Uri:          "TheBloke/Mistral-7B-Instruct-v0.2-AWQ",
+				Quantization: a1.AIModelQuantizationAWQ,
+			},
+		}}
+	})
+
+	create := func() (*engines.Engine, error) {
+		e, err := engines.Lookup(a1.AIEngineNameTGI)
+		Expect(err).NotTo(HaveOccurred())
+		return e, e.Validate(ai, models)
+	}
+
+	It("passes the model, quantization and port", func(ctx SpecContext) {
+		e, err := create()
+		Expect(err).NotTo(HaveOccurred())
+
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		c := d.Spec.Template.Spec.Containers[0]
+		Expect(c.Name).To(Equal(constants.ContainerEngineName))
+		Expect(c.Image).To(Equal(constants.ImageRepositoryTGI + ":latest"))
+		Expect(c.Args).To(Equal([]string{
+			"--model-id", "TheBloke/Mistral-7B-Instruct-v0.2-AWQ",
+			"--port", "8080",
+			"--quantize", "awq",
+		}))
+		Expect(c.ReadinessProbe.HTTPGet.Path).To(Equal("/health"))
+		Expect(c.StartupProbe.HTTPGet.Path).To(Equal("/health"))
+		Expect(d.Spec.Selector.MatchLabels).To(Equal(d.Spec.Template.Labels))
+	})
+
+	It("lets the engine options override the model", func(ctx SpecContext) {
+		models[0].Spec.Quantization = ""
+		ai.Spec.Engine.Options = map[string]string{constants.DtypeKey: "bfloat16"}
+
+		e, err := create()
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--dtype", "bfloat16"))
+	})
+
+	It("prefers the typed options", func(ctx SpecContext) {
+		tp := int32(2)
+		ai.Spec.Engine.Options = map[string]string{constants.QuantizationKey: "gptq"}
+		ai.Spec.Engine.TGI = &a1.TGIOptions{Quantization: "eetq", TensorParallelSize: &tp}
+
+		e, err := create()
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--quantize", "eetq", "--num-shard", "2"))
+	})
+
+	It("shards the model across the GPUs", func(ctx SpecContext) {
+		ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+		ai.Spec.Deployment.Resources.Requests = v1.ResourceList{
+			constants.NvidiaGPULabel: resource.MustParse("2"),
+		}
+
+		e, err := create()
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--num-shard", "2"))
+	})
+
+	DescribeTable("rejects options TGI doesn't accept",
+		func(dtype a1.AIModelDataType, quant a1.AIModelQuantization) {
+			models[0].Spec.DataType = dtype
+			models[0].Spec.Quantization = quant
+
+			_, err := create()
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("an unsupported dtype", a1.AIModelDataTypeInt8, a1.AIModelQuantization("")),
+		Entry("an unsupported quantization", a1.AIModelDataType(""), a1.AIModelQuantization("q4_0")),
+		Entry("both dtype and quantization", a1.AIModelDataTypeFloat16, a1.AIModelQuantizationAWQ),
+	)
+})
@@ -1,6 +1,7 @@
 package engines
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Triton struct {
@@ -22,6 +24,21 @@ type Triton struct {
 	Models       []aimodelmap.ResolvedModel
 }
 
+const tritonDefaultPort int32 = 8000
+
+func init() {
+	Register(Engine{
+		Name: a1.AIEngineNameTriton,
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.TensorRT
+		},
+		Validate: validateTriton,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewTriton(ai, m), nil
+		},
+	})
+}
+
 func NewTriton(ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) aideployment.MLEngine {
 	return &Triton{AIDeployment: ai, Models: m}
 
@@ -32,7 +49,11 @@ func (l *Triton) MetricsPort() int32 {
 }
 
 func (l *Triton) Port() int32 {
-	return 8000
+	if l.tensorRTLLM() != nil {
+		return tritonOpenAIPort
+	}
+
+	return tritonDefaultPort
 }
 
 func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
@@ -42,14 +63,11 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 		OwnerReferences: resources.GenOwner(owner),
 	}
 
-	imageTag := constants.ImageTagTritonDefault
-	if l.AIDeployment.Spec.Engine.Options[constants.ImageTagKey] != "" {
-		imageTag = l.AIDeployment.Spec.Engine.Options[constants.ImageTagKey]
-	}
-
-	imageRepository := constants.ImageRepositoryTriton
-	if l.AIDeployment.Spec.Engine.Options[constants.ImageRepositoryKey] != "" {
-		imageRepository = l.AIDeployment.Spec.Engine.Options[constants.ImageRepositoryKey]
+	defaults := engineDefaults(l.AIDeployment)
+	trtllm := l.tensorRTLLM()
+	// The default image doesn't have the TensorRT-LLM backend
+	if trtllm != nil && defaults.ImageTag == constants.ImageTagTritonDefault {
+		defaults.ImageTag = constants.ImageTagTritonTRTLLM
 	}
 
 	deployment := appsv1.Deployment{}
@@ -61,12 +79,16 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 	deployment.Spec.Replicas = l.AIDeployment.Spec.Deployment.Replicas
 	pod := &deployment.Spec.Template.Spec
 
+	if pod.ImagePullSecrets == nil {
+		pod.ImagePullSecrets = imagePullSecrets(l.AIDeployment, defaults.ImagePullSecrets)
+	}
+
 	serviceAccount := false
 
-	image := fmt.Sprintf("%s:%s", imageRepository, imageTag)
+	image := engineImage(l.AIDeployment, defaults)
 
 	expose := &v1.Container{
-		ImagePullPolicy: v1.PullAlways,
+		ImagePullPolicy: defaults.ImagePullPolicy,
 		Name:            constants.ContainerEngineName,
 		Image:           image,
 		Args: []string{
@@ -85,7 +107,7 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 			ProbeHandler: v1.ProbeHandler{
 				HTTPGet: &v1.HTTPGetAction{
 					Path: "/v2/health/ready",
-					Port: intstr.FromInt(int(l.Port())),
+					Port: intstr.FromInt(int(tritonDefaultPort)),
 				},
 			},
 		},
@@ -96,7 +118,7 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 			ProbeHandler: v1.ProbeHandler{
 				HTTPGet: &v1.HTTPGetAction{
 					Path: "/v2/health/ready",
-					Port: intstr.FromInt(int(l.Port())),
+					Port: intstr.FromInt(int(tritonDefaultPort)),
 				},
 			},
 		},
@@ -108,7 +130,7 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 			ProbeHandler: v1.ProbeHandler{
 				HTTPGet: &v1.HTTPGetAction{
 					Path: "/v2/health/live",
-					Port: intstr.FromInt(int(l.Port())),
+					Port: intstr.FromInt(int(tritonDefaultPort)),
 				},
 			},
 		},
@@ -126,19 +148,27 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 		},
 	})
 
-	for _, m := range l.Models {
+	models := l.Models
+	if trtllm != nil {
+		l.tensorRTLLMRepository(pod, expose, image, defaults.ImagePullPolicy)
+		// The model repository is laid out by the TensorRT-LLM init containers
+		models = nil
+	}
+
+	for _, m := range models {
 		// if the URL doesn't point to a tar file
 		if strings.HasPrefix(m.Spec.Uri, "http") && !strings.Contains(m.Spec.Uri, ".tar") {
 			pod.InitContainers = append(pod.InitContainers, v1.Container{
-				ImagePullPolicy: v1.PullAlways,
+				ImagePullPolicy: defaults.ImagePullPolicy,
 				Name:            fmt.Sprintf("init-%s", m.Name),
 				Image:           image,
 				Command:         []string{"sh", "-c"},
 				// needs to be in a single line as sh -c accepts a single input
-				Args: []string{"curl --create-dirs -O --output-dir /models/$MODEL_NAME/1 $MODEL_PATH"},
+				Args: []string{downloadOnce("$MODEL_PATH", "/models/$MODEL_NAME/1/$MODEL_FILE")},
 				Env: []v1.EnvVar{
 					{Name: "MODEL_NAME", Value: m.Name},
 					{Name: "MODEL_PATH", Value: m.Spec.Uri},
+					{Name: "MODEL_FILE", Value: uriFileName(m.Spec.Uri)},
 				},
 				VolumeMounts: []v1.VolumeMount{
 					{
@@ -149,12 +179,12 @@ func (l *Triton) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 			})
 		} else if strings.HasPrefix(m.Spec.Uri, "http") {
 			pod.InitContainers = append(pod.InitContainers, v1.Container{
-				ImagePullPolicy: v1.PullAlways,
+				ImagePullPolicy: defaults.ImagePullPolicy,
 				Name:            fmt.Sprintf("init-%s", m.Name),
 				Image:           image,
 				Command:         []string{"sh", "-c"},
 				// needs to be in a single line as sh -c accepts a single input
-				Args: []string{"curl -s -L $MODEL_PATH | tar xvz - -C /models"},
+				Args: []string{extractOnce("$MODEL_PATH", "/models", "$MODEL_NAME")},
 				Env: []v1.EnvVar{
 					{Name: "MODEL_NAME", Value: m.Name},
 					{Name: "MODEL_PATH", Value: m.Spec.Uri},
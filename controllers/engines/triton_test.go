@@ -0,0 +1,85 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("Triton with TensorRT-LLM", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameTriton},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name: "llama",
+			Spec: a1.AIModelSpec{
+				Uri: "https://models.example.com/llama-3-8b-trtllm.tar.gz",
+				TensorRTLLM: &a1.TensorRTLLM{
+					EngineDir:    "llama/engine",
+					MaxBatchSize: 64,
+				},
+			},
+		}}
+	})
+
+	render := func(ctx SpecContext) *v1.PodSpec {
+		e, err := engines.Lookup(a1.AIEngineNameTriton)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mle.Port()).To(Equal(int32(9000)))
+
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return &d.Spec.Template.Spec
+	}
+
+	It("lays out the ensemble repository from the model spec", func(ctx SpecContext) {
+		pod := render(ctx)
+
+		Expect(pod.InitContainers).To(HaveLen(2))
+		Expect(pod.InitContainers[0].Env).To(ContainElement(v1.EnvVar{Name: "MODEL_PATH", Value: models[0].Spec.Uri}))
+		Expect(pod.InitContainers[1].Image).To(Equal(constants.ImageTritonTRTLLMRepository))
+		Expect(pod.InitContainers[1].Env).To(ContainElements(
+			v1.EnvVar{Name: "ENGINE_DIR", Value: "/engines/llama/engine"},
+			v1.EnvVar{Name: "TOKENIZER_DIR", Value: "/engines/tokenizer"},
+			v1.EnvVar{Name: "MAX_BATCH_SIZE", Value: "64"},
+		))
+
+		c := pod.Containers[0]
+		Expect(c.Image).To(Equal(constants.ImageRepositoryTriton + ":" + constants.ImageTagTritonTRTLLM))
+		Expect(c.Args).To(ContainElements("--tokenizer", "/engines/tokenizer", "--openai-port", "9000"))
+		Expect(c.ReadinessProbe.HTTPGet.Port.IntValue()).To(Equal(8000))
+	})
+
+	DescribeTable("rejects models it can't serve",
+		func(change func()) {
+			change()
+
+			e, err := engines.Lookup(a1.AIEngineNameTriton)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(HaveOccurred())
+		},
+		Entry("a URI which isn't http", func() { models[0].Spec.Uri = "s3://models/llama.tar.gz" }),
+		Entry("an engine dir outside the tarball", func() { models[0].Spec.TensorRTLLM.EngineDir = "../engine" }),
+		Entry("more than one model", func() {
+			models = append(models, aimodelmap.ResolvedModel{Name: "other", Spec: a1.AIModelSpec{Uri: "https://example.com/m.onnx"}})
+		}),
+	)
+})
@@ -0,0 +1,160 @@
+package engines
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	// Port of Triton's OpenAI compatible frontend
+	tritonOpenAIPort int32 = 9000
+
+	tritonEnginesPath  = "/engines"
+	tritonFrontendPath = "/frontend"
+
+	tritonDefaultEngineDir    = "engine"
+	tritonDefaultTokenizerDir = "tokenizer"
+	tritonDefaultMaxBatchSize = 8
+)
+
+// tritonRepositoryScript copies the in-flight batching ensemble from the
+// TensorRT-LLM backend into the model repository and fills in the parameters
+// of each config.pbtxt. It also copies the OpenAI frontend which isn't
+// shipped in the Triton image. Both are in constants.ImageTritonTRTLLMRepository
+// so that nothing is downloaded apart from the model.
+const tritonRepositoryScript = `set -e
+for m in ensemble preprocessing postprocessing tensorrt_llm; do
+  cp -r /opt/tensorrtllm_backend/all_models/inflight_batcher_llm/$m /models/
+done
+fill() { python3 /opt/tensorrtllm_backend/tools/fill_template.py -i /models/$1/config.pbtxt $2; }
+fill preprocessing tokenizer_dir:$TOKENIZER_DIR,triton_max_batch_size:$MAX_BATCH_SIZE,preprocessing_instance_count:1
+fill postprocessing tokenizer_dir:$TOKENIZER_DIR,triton_max_batch_size:$MAX_BATCH_SIZE,postprocessing_instance_count:1
+fill tensorrt_llm triton_backend:tensorrtllm,triton_max_batch_size:$MAX_BATCH_SIZE,decoupled_mode:True,engine_dir:$ENGINE_DIR,max_queue_delay_microseconds:0,batching_strategy:inflight_fused_batching,encoder_input_features_data_type:TYPE_FP16,logits_datatype:TYPE_FP32
+fill ensemble triton_max_batch_size:$MAX_BATCH_SIZE,logits_datatype:TYPE_FP32
+cp -r /opt/frontend/. ` + tritonFrontendPath + `/`
+
+func validateTriton(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	for _, m := range models {
+		trtllm := m.Spec.TensorRTLLM
+		if trtllm == nil {
+			continue
+		}
+
+		if len(models) != 1 {
+			return fmt.Errorf("triton: a TensorRT-LLM model must be the only model in the deployment")
+		}
+		if !isHTTP(m.Spec.Uri) {
+			return fmt.Errorf("triton: a TensorRT-LLM model's URI must be an http(s) URL of a tarball")
+		}
+		for _, dir := range []string{trtllm.EngineDir, trtllm.TokenizerDir} {
+			if dir != "" && !filepath.IsLocal(dir) {
+				return fmt.Errorf("triton: %q must be a relative path inside the model's tarball", dir)
+			}
+		}
+		if trtllm.MaxBatchSize < 0 {
+			return fmt.Errorf("triton: maxBatchSize must be positive")
+		}
+	}
+
+	return nil
+}
+
+// tensorRTLLM returns the TensorRT-LLM settings when Triton is serving a
+// TensorRT-LLM engine
+func (l *Triton) tensorRTLLM() *a1.TensorRTLLM {
+	if len(l.Models) != 1 {
+		return nil
+	}
+
+	return l.Models[0].Spec.TensorRTLLM
+}
+
+// tensorRTLLMRepository downloads the engine, lays out the ensemble model
+// repository and runs Triton behind its OpenAI compatible frontend
+func (l *Triton) tensorRTLLMRepository(pod *v1.PodSpec, expose *v1.Container, image string, pullPolicy v1.PullPolicy) {
+	m := l.Models[0]
+	trtllm := m.Spec.TensorRTLLM
+
+	engineDir := tritonDefaultEngineDir
+	if trtllm.EngineDir != "" {
+		engineDir = trtllm.EngineDir
+	}
+	tokenizerDir := tritonDefaultTokenizerDir
+	if trtllm.TokenizerDir != "" {
+		tokenizerDir = trtllm.TokenizerDir
+	}
+	maxBatchSize := int32(tritonDefaultMaxBatchSize)
+	if trtllm.MaxBatchSize > 0 {
+		maxBatchSize = trtllm.MaxBatchSize
+	}
+
+	enginePath := path.Join(tritonEnginesPath, engineDir)
+	tokenizerPath := path.Join(tritonEnginesPath, tokenizerDir)
+
+	modelsMount := v1.VolumeMount{Name: "models", MountPath: "/models"}
+	enginesMount := v1.VolumeMount{Name: "engines", MountPath: tritonEnginesPath}
+	frontendMount := v1.VolumeMount{Name: "frontend", MountPath: tritonFrontendPath}
+
+	pod.InitContainers = append(pod.InitContainers,
+		v1.Container{
+			ImagePullPolicy: pullPolicy,
+			Name:            fmt.Sprintf("init-%s", m.Name),
+			Image:           image,
+			Command:         []string{"sh", "-c"},
+			Args:            []string{extractOnce("$MODEL_PATH", tritonEnginesPath, "$MODEL_NAME")},
+			Env: []v1.EnvVar{
+				{Name: "MODEL_NAME", Value: m.Name},
+				{Name: "MODEL_PATH", Value: m.Spec.Uri},
+			},
+			VolumeMounts: []v1.VolumeMount{enginesMount},
+		},
+		v1.Container{
+			ImagePullPolicy: pullPolicy,
+			Name:            "init-repository",
+			Image:           constants.ImageTritonTRTLLMRepository,
+			Command:         []string{"sh", "-c"},
+			Args:            []string{tritonRepositoryScript},
+			Env: []v1.EnvVar{
+				{Name: "ENGINE_DIR", Value: enginePath},
+				{Name: "TOKENIZER_DIR", Value: tokenizerPath},
+				{Name: "MAX_BATCH_SIZE", Value: fmt.Sprint(maxBatchSize)},
+			},
+			VolumeMounts: []v1.VolumeMount{modelsMount, frontendMount},
+		},
+	)
+
+	// The frontend runs Triton in process and keeps its KServe HTTP
+	// endpoints, which the probes use
+	expose.Command = []string{"python3", tritonFrontendPath + "/openai/openai_frontend/main.py"}
+	expose.Args = []string{
+		"--model-repository", "/models",
+		"--tokenizer", tokenizerPath,
+		"--backend", "tensorrtllm",
+		"--openai-port", fmt.Sprint(tritonOpenAIPort),
+		"--enable-kserve-frontends",
+		"--kserve-http-port", fmt.Sprint(tritonDefaultPort),
+	}
+	expose.Env = append(expose.Env, v1.EnvVar{Name: "PYTHONPATH", Value: tritonFrontendPath + "/packages"})
+	expose.Ports = append(expose.Ports,
+		v1.ContainerPort{Name: "openai", ContainerPort: tritonOpenAIPort},
+		v1.ContainerPort{Name: "http", ContainerPort: tritonDefaultPort},
+	)
+	expose.VolumeMounts = append(expose.VolumeMounts, enginesMount, frontendMount)
+
+	for _, name := range []string{"engines", "frontend"} {
+		pod.Volumes = append(pod.Volumes, v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+	sharedMemory(pod, expose)
+}
@@ -1,10 +1,56 @@
 package engines
 
 import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
 	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/config"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+	"github.com/premAI-io/prem-operator/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// engineDefaults returns the operator config for the AIDeployment's engine
+func engineDefaults(ai *a1.AIDeployment) config.EngineDefaults {
+	return config.Get().Engine(ai.Spec.Engine.Name)
+}
+
+// engineImage returns the image set in the AIDeployment's engine image or
+// options falling back to the operator config and then the built in defaults
+func engineImage(ai *a1.AIDeployment, defaults config.EngineDefaults) string {
+	var image a1.EngineImage
+	if ai.Spec.Engine.Image != nil {
+		image = *ai.Spec.Engine.Image
+	}
+
+	repository := defaults.ImageRepository
+	if image.Repository != "" {
+		repository = image.Repository
+	} else if r := ai.Spec.Engine.Options[constants.ImageRepositoryKey]; r != "" {
+		repository = r
+	}
+
+	tag := defaults.ImageTag
+	if image.Tag != "" {
+		tag = image.Tag
+	} else if t := ai.Spec.Engine.Options[constants.ImageTagKey]; t != "" {
+		tag = t
+	}
+
+	return fmt.Sprintf("%s:%s", repository, tag)
+}
+
 func mergeProbe(src *a1.Probe, dst *v1.Probe) {
 	if src == nil {
 		return
@@ -26,3 +72,349 @@ func mergeProbe(src *a1.Probe, dst *v1.Probe) {
 		dst.FailureThreshold = src.FailureThreshold
 	}
 }
+
+// imagePullSecrets returns the AIDeployment's pull secrets, or else the
+// engine's defaults
+func imagePullSecrets(ai *a1.AIDeployment, defaults []v1.LocalObjectReference) []v1.LocalObjectReference {
+	if ai.Spec.Deployment.ImagePullSecrets != nil {
+		return ai.Spec.Deployment.ImagePullSecrets
+	}
+
+	return defaults
+}
+
+// newDeployment starts an engine's Deployment from the AIDeployment's pod
+// template
+func newDeployment(ai *a1.AIDeployment, defaults config.EngineDefaults) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{}
+	if ai.Spec.Deployment.PodTemplate != nil {
+		deployment.Spec.Template = *ai.Spec.Deployment.PodTemplate.DeepCopy()
+	}
+	deployment.Spec.Replicas = ai.Spec.Deployment.Replicas
+
+	pod := &deployment.Spec.Template.Spec
+	if pod.ImagePullSecrets == nil {
+		pod.ImagePullSecrets = imagePullSecrets(ai, defaults.ImagePullSecrets)
+	}
+
+	serviceAccount := false
+	pod.AutomountServiceAccountToken = &serviceAccount
+
+	return deployment
+}
+
+// finishDeployment sets the metadata, labels and selector of an engine's
+// Deployment
+func finishDeployment(ai *a1.AIDeployment, owner metav1.Object, deployment *appsv1.Deployment) {
+	deploymentLabels := resources.GenDefaultLabels(ai.Name)
+	deployment.Spec.Template.Labels = utils.MergeMaps(
+		deploymentLabels,
+		deployment.Spec.Template.Labels,
+		ai.Spec.Deployment.Labels,
+	)
+
+	deployment.Spec.Template.Annotations = utils.MergeMaps(
+		deployment.Spec.Template.Annotations,
+		ai.Spec.Deployment.Annotations,
+	)
+
+	deployment.ObjectMeta = metav1.ObjectMeta{
+		Name:            ai.Name,
+		Namespace:       ai.Namespace,
+		OwnerReferences: resources.GenOwner(owner),
+	}
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: deploymentLabels}
+}
+
+// setProbes gives an engine which may download its model on start up the
+// usual probes, merged with the probe settings in the AIDeployment
+func setProbes(ai *a1.AIDeployment, c *v1.Container, handler v1.ProbeHandler) {
+	c.StartupProbe = &v1.Probe{
+		InitialDelaySeconds: 3,
+		PeriodSeconds:       10,
+		FailureThreshold:    180,
+		ProbeHandler:        handler,
+	}
+	c.ReadinessProbe = &v1.Probe{
+		FailureThreshold: 3,
+		ProbeHandler:     handler,
+	}
+	c.LivenessProbe = &v1.Probe{
+		PeriodSeconds:    30,
+		TimeoutSeconds:   15,
+		FailureThreshold: 10,
+		ProbeHandler:     handler,
+	}
+
+	mergeProbe(ai.Spec.Deployment.StartupProbe, c.StartupProbe)
+	mergeProbe(ai.Spec.Deployment.ReadinessProbe, c.ReadinessProbe)
+	mergeProbe(ai.Spec.Deployment.LivenessProbe, c.LivenessProbe)
+}
+
+// httpGet is a probe handler for a path on the engine's port
+func httpGet(path string, port int32) v1.ProbeHandler {
+	return v1.ProbeHandler{
+		HTTPGet: &v1.HTTPGetAction{
+			Path: path,
+			Port: intstr.FromInt(int(port)),
+		},
+	}
+}
+
+// sharedMemory mounts a memory backed /dev/shm, the default 64MB is too
+// small for engines which use NCCL or PyTorch data loaders
+func sharedMemory(pod *v1.PodSpec, c *v1.Container) {
+	// The containers of a pod share the volume
+	if !slices.ContainsFunc(pod.Volumes, func(v v1.Volume) bool { return v.Name == "shm" }) {
+		pod.Volumes = append(pod.Volumes, v1.Volume{
+			Name: "shm",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory},
+			},
+		})
+	}
+	c.VolumeMounts = append(c.VolumeMounts, v1.VolumeMount{Name: "shm", MountPath: "/dev/shm"})
+}
+
+// modelOption returns the engine option, falling back to the model's spec
+func modelOption(ai *a1.AIDeployment, key, fromModel string) string {
+	if v, ok := ai.Spec.Engine.Options[key]; ok {
+		return v
+	}
+
+	return fromModel
+}
+
+// typedOption returns the typed engine option if it is set, or else the
+// engine option with the key
+func typedOption(typed *int32, ai *a1.AIDeployment, key string) string {
+	if typed != nil {
+		return fmt.Sprint(*typed)
+	}
+
+	return ai.Spec.Engine.Options[key]
+}
+
+// downloadOnce returns a shell command which downloads url to file unless
+// the file is already there, so a restarted pod reuses a persistent models
+// volume. The download is only renamed to file once it is complete. url and
+// file are shell words, usually environment variables.
+func downloadOnce(url, file string) string {
+	return fmt.Sprintf(
+		`[ -f "%[2]s" ] || { mkdir -p "$(dirname "%[2]s")" && curl -fL -o "%[2]s.part" "%[1]s" && mv "%[2]s.part" "%[2]s"; }`,
+		url, file,
+	)
+}
+
+// extractOnce is downloadOnce for a tarball which is extracted into dir, a
+// marker named after name records that it was extracted completely
+func extractOnce(url, dir, name string) string {
+	return fmt.Sprintf(
+		`[ -f "%[2]s/.%[3]s.done" ] || { mkdir -p "%[2]s" && curl -fL -o "%[2]s/.%[3]s.tar.gz" "%[1]s" && `+
+			`tar xzf "%[2]s/.%[3]s.tar.gz" -C "%[2]s" && rm "%[2]s/.%[3]s.tar.gz" && touch "%[2]s/.%[3]s.done"; }`,
+		url, dir, name,
+	)
+}
+
+// uriFileName is the name of the file an http(s) URI points to, e.g.
+// model.onnx for https://example.com/v1/model.onnx?download=true
+func uriFileName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "model"
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return "model"
+	}
+
+	return name
+}
+
+// downloadContainer returns an init container which downloads a model with
+// an http(s) URI into the models volume, in the same way as LocalAI
+func downloadContainer(name, image string, pullPolicy v1.PullPolicy, uri, path string, mount v1.VolumeMount) v1.Container {
+	return v1.Container{
+		ImagePullPolicy: pullPolicy,
+		Name:            name,
+		Image:           image,
+		Command:         []string{"sh", "-c"},
+		Args:            []string{downloadOnce("$MODEL_PATH", "$MODEL_FILE")},
+		Env: []v1.EnvVar{
+			{Name: "MODEL_FILE", Value: path},
+			{Name: "MODEL_PATH", Value: uri},
+		},
+		VolumeMounts: []v1.VolumeMount{mount},
+	}
+}
+
+// isHTTP is true if the model can be fetched by downloadContainer
+func isHTTP(uri string) bool {
+	return strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+}
+
+// usesCUDA is true if the AIDeployment is scheduled on Nvidia GPUs
+func usesCUDA(ai *a1.AIDeployment) bool {
+	acc := ai.Spec.Deployment.Accelerator
+	return acc != nil && acc.Interface == a1.AcceleratorInterfaceCUDA
+}
+
+// intOptions checks that the engine options with the keys are integers
+func intOptions(ai *a1.AIDeployment, keys ...string) error {
+	for _, k := range keys {
+		if v, ok := ai.Spec.Engine.Options[k]; ok {
+			if _, err := strconv.Atoi(v); err != nil {
+				return fmt.Errorf("engine option %s must be an integer: %q", k, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tensorParallelSize is the typed tensor parallel size if it is set, or
+// else the tensorParallelSize engine option or the number of GPUs given to
+// the engine's pod. It is empty when the model isn't split.
+func tensorParallelSize(typed *int32, ai *a1.AIDeployment) string {
+	if typed != nil {
+		return fmt.Sprint(*typed)
+	}
+
+	return modelTensorParallelSize(ai, 1)
+}
+
+// modelTensorParallelSize is tensorParallelSize for one of the containers of
+// an engine which runs a container per model, the GPUs are split between them
+func modelTensorParallelSize(ai *a1.AIDeployment, models int) string {
+	if tp := ai.Spec.Engine.Options[constants.TensorParallelSizeKey]; tp != "" {
+		return tp
+	}
+
+	// Scheduling reports the error when the GPUs can't be worked out
+	gpus, err := aideployment.GPUsPerPod(ai.Spec)
+	if err != nil || gpus/int64(models) < 2 {
+		return ""
+	}
+
+	return fmt.Sprint(gpus / int64(models))
+}
+
+// validateTensorParallelSize checks the tensorParallelSize engine option
+func validateTensorParallelSize(ai *a1.AIDeployment) error {
+	if tp, ok := ai.Spec.Engine.Options[constants.TensorParallelSizeKey]; ok {
+		if n, err := strconv.Atoi(tp); err != nil || n < 1 {
+			return fmt.Errorf("engine option %s must be a positive integer: %q", constants.TensorParallelSizeKey, tp)
+		}
+	}
+
+	return nil
+}
+
+// validateAdapters checks that the LoRA adapters' names don't clash, as
+// each is served as its own model
+func validateAdapters(models []aimodelmap.ResolvedModel) error {
+	names := map[string]bool{}
+	for _, m := range models {
+		for _, a := range m.Spec.Adapters {
+			if a.Name == "" || a.Uri == "" {
+				return fmt.Errorf("model %s has an adapter without a name or uri", m.Name)
+			}
+			if names[a.Name] {
+				return fmt.Errorf("adapter %s is listed more than once", a.Name)
+			}
+			names[a.Name] = true
+		}
+	}
+
+	return nil
+}
+
+// servedNames returns the names the model is served under, the first is the
+// one the engine reports. It is nil when the model is served under its URI.
+func servedNames(m aimodelmap.ResolvedModel) []string {
+	if m.Spec.ServedName == "" && len(m.Spec.Aliases) == 0 {
+		return nil
+	}
+
+	name := m.Spec.ServedName
+	if name == "" {
+		name = m.Spec.Uri
+	}
+
+	return append([]string{name}, m.Spec.Aliases...)
+}
+
+const (
+	chatTemplatesVolume = "chat-templates"
+	chatTemplatesPath   = "/chat-templates"
+)
+
+// validateChatTemplates checks that the chat templates can be mounted from
+// the AIModelMaps' ConfigMaps, which a pod can only do in its own namespace
+func validateChatTemplates(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	for _, m := range models {
+		if m.Spec.ChatTemplate == "" {
+			continue
+		}
+		if m.Variant == "inline" {
+			return fmt.Errorf("inline model %s has a chat template, but we haven't implemented generating ConfigMaps for inline models", m.Name)
+		}
+		if m.Namespace != ai.Namespace {
+			return fmt.Errorf(
+				"model %s/%s has a chat template, it can only be used from an AIModelMap in the AIDeployment's namespace %s",
+				m.Namespace, m.Name, ai.Namespace,
+			)
+		}
+	}
+
+	return nil
+}
+
+// addChatTemplates mounts the models' chat templates from the ConfigMaps of
+// their AIModelMaps into the pod, see chatTemplatePath
+func addChatTemplates(engine a1.AIEngineName, pod *v1.PodSpec, models []aimodelmap.ResolvedModel) {
+	sources := []v1.VolumeProjection{}
+	for _, m := range models {
+		if m.Spec.ChatTemplate == "" {
+			continue
+		}
+
+		sources = append(sources, v1.VolumeProjection{
+			ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: m.Name},
+				Items: []v1.KeyToPath{{
+					Key:  aimodelmap.FmtConfigMapKey(engine, m.Variant, constants.AIModelMapSpecChatTemplate),
+					Path: m.HostName + ".jinja",
+				}},
+			},
+		})
+	}
+
+	if len(sources) == 0 {
+		return
+	}
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: chatTemplatesVolume,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{Sources: sources},
+		},
+	})
+}
+
+// chatTemplatePath mounts the chat templates into the container and returns
+// the path of the model's template, it is empty if the model has none
+func chatTemplatePath(c *v1.Container, m aimodelmap.ResolvedModel) string {
+	if m.Spec.ChatTemplate == "" {
+		return ""
+	}
+
+	c.VolumeMounts = append(c.VolumeMounts, v1.VolumeMount{
+		Name:      chatTemplatesVolume,
+		MountPath: chatTemplatesPath,
+		ReadOnly:  true,
+	})
+
+	return chatTemplatesPath + "/" + m.HostName + ".jinja"
+}
@@ -1,29 +1,30 @@
 package engines
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strconv"
 
+	"github.com/go-logr/logr"
 	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
 	"github.com/premAI-io/prem-operator/controllers/aideployment"
 	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
 	"github.com/premAI-io/prem-operator/controllers/constants"
 	"github.com/premAI-io/prem-operator/controllers/resources"
 	"github.com/premAI-io/prem-operator/pkg/utils"
-	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
 	vllmContainerVolumePath = "/root/.cache/huggingface"
 )
 
-const (
-	vllmImageFormat = "%s:%s"
-)
-
 var (
 	ErrModelsNotSpecified = fmt.Errorf("models not specified")
 	ErrorOnlyOneModel     = fmt.Errorf("only one model can be specified")
@@ -31,7 +32,9 @@ var (
 
 type vllmAi struct {
 	// image of the vllm engine
-	engineImage string
+	engineImage      string
+	imagePullPolicy  v1.PullPolicy
+	imagePullSecrets []v1.LocalObjectReference
 	// environment variables to pass to the vllm engine
 	engineEnvVars []v1.EnvVar
 
@@ -42,114 +45,334 @@ type vllmAi struct {
 
 	// used to customize the deployment
 	deploymentOptions *a1.AIDeployment
-	model             aimodelmap.ResolvedModel
+	// each model is served by its own container when there are several
+	models []aimodelmap.ResolvedModel
+	// proposes tokens for the model when speculative decoding is used
+	draftModel *aimodelmap.ResolvedModel
+
+	lg logr.Logger
 }
 
-func NewVllmAi(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
-	if len(models) == 0 {
-		return nil, ErrModelsNotSpecified
+const vllmDefaultPort int32 = 8000
+
+func init() {
+	Register(Engine{
+		Name:             a1.AIEngineNameVLLM,
+		Options:          []string{constants.DtypeKey, constants.QuantizationKey, constants.TensorParallelSizeKey},
+		ModelMapVariants: vllmVariants,
+		Validate:         validateVllm,
+		New: func(ctx context.Context, c ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			draft, err := resolveDraftModel(ctx, c, ai, m)
+			if err != nil {
+				return nil, err
+			}
+
+			return newVllmAi(log.FromContext(ctx), ai, m, draft)
+		},
+	})
+}
+
+func vllmVariants(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+	return spec.Vllm
+}
+
+// resolveDraftModel finds the draft model, it is nil if there isn't one
+func resolveDraftModel(
+	ctx context.Context,
+	c ctrlClient.Client,
+	ai *a1.AIDeployment,
+	models []aimodelmap.ResolvedModel,
+) (*aimodelmap.ResolvedModel, error) {
+	opts := ai.Spec.Engine.VLLM
+	if opts == nil || opts.DraftModel == nil || len(models) == 0 {
+		return nil, nil
 	}
 
-	if len(models) > 1 {
-		return nil, ErrorOnlyOneModel
+	draft, err := aimodelmap.ResolveModel(opts.DraftModel, ai, ctx, c, vllmVariants)
+	if err != nil {
+		return nil, fmt.Errorf("vllm: draft model: %w", err)
+	}
+
+	// An undeclared tokenizer can't be checked, vLLM still refuses to start if
+	// the vocabularies differ
+	want, got := models[0].Spec.TokenizerFamily, draft.Spec.TokenizerFamily
+	if want != "" && got != "" && want != got {
+		return nil, fmt.Errorf("vllm: the draft model's tokenizer family %s isn't the model's %s", got, want)
 	}
 
-	model := models[0]
-	imageTag := "latest"
-	imageRepo := constants.ImageRepositoryVllm
-	if ai.Spec.Engine.Options[constants.ImageTagKey] != "" {
-		imageTag = ai.Spec.Engine.Options[constants.ImageTagKey]
+	if q := string(draft.Spec.Quantization); q != "" && !slices.Contains(vllmQuantizations, q) {
+		return nil, fmt.Errorf("vllm: unsupported draft model quantization %q", q)
 	}
-	if ai.Spec.Engine.Options[constants.ImageRepositoryKey] != "" {
-		imageRepo = ai.Spec.Engine.Options[constants.ImageRepositoryKey]
+
+	// vLLM only splits the draft model across one GPU or all of the model's
+	if dtp := opts.DraftTensorParallelSize; dtp != nil && *dtp != 1 {
+		tp := vllmTensorParallelSize(opts, ai, len(models))
+		if tp == "" {
+			tp = "1"
+		}
+		if fmt.Sprint(*dtp) != tp {
+			return nil, fmt.Errorf("vllm: draftTensorParallelSize must be 1 or the model's tensor parallel size %s", tp)
+		}
 	}
-	engineImage := fmt.Sprintf(vllmImageFormat, imageRepo, imageTag)
+
+	return draft, nil
+}
+
+func NewVllmAi(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+	return newVllmAi(log.Log, ai, models, nil)
+}
+
+func newVllmAi(
+	lg logr.Logger,
+	ai *a1.AIDeployment,
+	models []aimodelmap.ResolvedModel,
+	draft *aimodelmap.ResolvedModel,
+) (aideployment.MLEngine, error) {
+	if len(models) == 0 {
+		return nil, ErrModelsNotSpecified
+	}
+
+	defaults := engineDefaults(ai)
 
 	return &vllmAi{
-		engineImage: engineImage,
+		engineImage:      engineImage(ai, defaults),
+		imagePullPolicy:  defaults.ImagePullPolicy,
+		imagePullSecrets: imagePullSecrets(ai, defaults.ImagePullSecrets),
 
 		resourceName:      ai.Name,
 		namespace:         ai.Namespace,
 		engineEnvVars:     ai.Spec.Env,
 		deploymentOptions: ai,
-		model:             model,
+		models:            models,
+		draftModel:        draft,
+		lg:                lg,
 	}, nil
 }
 
-func (v *vllmAi) Port() int32 {
-	return 8000
+func validateVllm(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := validateMultipleModels(ai, models); err != nil {
+		return fmt.Errorf("vllm: %w", err)
+	}
+
+	// The router finds a model's container by the model's name
+	if len(models) > 1 {
+		if ai.Spec.Engine.VLLM != nil && ai.Spec.Engine.VLLM.ServedModelName != "" {
+			return fmt.Errorf("vllm: servedModelName can only be set with one model")
+		}
+		if ai.Spec.Engine.VLLM != nil && ai.Spec.Engine.VLLM.DraftModel != nil {
+			return fmt.Errorf("vllm: draftModel can only be set with one model")
+		}
+
+		names := map[string]bool{}
+		for _, m := range models {
+			for _, name := range vllmModelNames(m) {
+				if names[name] {
+					return fmt.Errorf("vllm: model name %s is used more than once", name)
+				}
+				names[name] = true
+			}
+		}
+	}
+
+	if ai.Spec.Distributed != nil && ai.Spec.Deployment.Replicas != nil && *ai.Spec.Deployment.Replicas > 1 {
+		return fmt.Errorf("vllm: a distributed deployment can only have one replica")
+	}
+
+	// The workers would need the adapters as well
+	if ai.Spec.Distributed != nil && len(models[0].Spec.Adapters) > 0 {
+		return fmt.Errorf("vllm: a distributed deployment can't serve LoRA adapters")
+	}
+	if ai.Spec.Distributed != nil && ai.Spec.Engine.VLLM != nil && ai.Spec.Engine.VLLM.DraftModel != nil {
+		return fmt.Errorf("vllm: a distributed deployment can't use a draft model")
+	}
+
+	if err := validateAdapters(models); err != nil {
+		return fmt.Errorf("vllm: %w", err)
+	}
+
+	if err := validateChatTemplates(ai, models); err != nil {
+		return fmt.Errorf("vllm: %w", err)
+	}
+
+	for _, m := range models {
+		opts := vllmOptions(ai, m)
+		if opts.Dtype != "" && !slices.Contains(vllmDtypes, opts.Dtype) {
+			return fmt.Errorf("vllm: unsupported dtype %q", opts.Dtype)
+		}
+		if opts.Quantization != "" && !slices.Contains(vllmQuantizations, opts.Quantization) {
+			return fmt.Errorf("vllm: unsupported quantization %q", opts.Quantization)
+		}
+	}
+
+	opts := vllmOptions(ai, models[0])
+	if u := opts.GPUMemoryUtilization; u != "" {
+		if f, err := strconv.ParseFloat(u, 64); err != nil || f <= 0 || f > 1 {
+			return fmt.Errorf("vllm: gpuMemoryUtilization must be in (0, 1]: %q", u)
+		}
+	}
+
+	return validateTensorParallelSize(ai)
 }
 
-func (v *vllmAi) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
-	log.Debug("Creating deployment for vllm engine, model: ", v.model.Name)
-	healthProbeHandler := v1.ProbeHandler{
-		HTTPGet: &v1.HTTPGetAction{
-			Path: "/health",
-			Port: intstr.FromInt(int(v.Port())),
-		},
+// The values vLLM accepts, these match the enums of a1.VLLMOptions
+var (
+	vllmDtypes        = []string{"auto", "half", "float16", "bfloat16", "float", "float32"}
+	vllmQuantizations = []string{
+		"aqlm", "awq", "awq_marlin", "bitsandbytes", "compressed-tensors", "deepspeedfp", "experts_int8",
+		"fbgemm_fp8", "fp8", "gguf", "gptq", "gptq_marlin", "gptq_marlin_24", "marlin", "squeezellm",
 	}
+)
 
-	container := v1.Container{
-		ImagePullPolicy: v1.PullAlways,
-		Name:            constants.ContainerEngineName,
-		Image:           v.engineImage,
-		Env:             v.engineEnvVars,
-		VolumeMounts: []v1.VolumeMount{
-			{
-				Name:      "models",
-				MountPath: vllmContainerVolumePath,
-			},
-		},
-		Args: []string{
-			"--model", v.model.Spec.Uri,
-		},
-		StartupProbe: &v1.Probe{
-			InitialDelaySeconds: 3,
-			PeriodSeconds:       1,
-			FailureThreshold:    120,
-			ProbeHandler:        healthProbeHandler,
-		},
-		ReadinessProbe: &v1.Probe{
-			FailureThreshold: 3,
-			ProbeHandler:     healthProbeHandler,
-		},
-		LivenessProbe: &v1.Probe{
-			PeriodSeconds:    30,
-			TimeoutSeconds:   15,
-			FailureThreshold: 10,
-			ProbeHandler:     healthProbeHandler,
-		},
+// vllmOptions returns the typed options with the dtype and quantization
+// filled in from the engine options or else the model's spec
+func vllmOptions(ai *a1.AIDeployment, model aimodelmap.ResolvedModel) a1.VLLMOptions {
+	var opts a1.VLLMOptions
+	if ai.Spec.Engine.VLLM != nil {
+		opts = *ai.Spec.Engine.VLLM
 	}
 
-	engineOpts := make(map[string]string)
-	if v.model.Spec.DataType != "" {
-		engineOpts[constants.DtypeKey] = string(v.model.Spec.DataType)
+	if opts.Dtype == "" {
+		opts.Dtype = modelOption(ai, constants.DtypeKey, string(model.Spec.DataType))
 	}
-	if v.model.Spec.Quantization != "" {
-		engineOpts[constants.QuantizationKey] = string(v.model.Spec.Quantization)
+	if opts.Quantization == "" {
+		opts.Quantization = modelOption(ai, constants.QuantizationKey, string(model.Spec.Quantization))
 	}
-	v.deploymentOptions.Spec.Engine.Options = utils.MergeMaps(engineOpts, v.deploymentOptions.Spec.Engine.Options)
 
-	if dtype, ok := v.deploymentOptions.Spec.Engine.Options[constants.DtypeKey]; ok {
-		if utils.IsAlphanumeric(dtype) {
-			container.Args = append(container.Args, "--dtype", dtype)
+	return opts
+}
+
+// vllmArgs renders the typed options apart from the tensor parallel size,
+// which depends on whether the deployment is distributed, and the served
+// model name, see vllmServedNames
+func vllmArgs(opts *a1.VLLMOptions) []string {
+	var args []string
+	if opts.Dtype != "" {
+		args = append(args, "--dtype", opts.Dtype)
+	}
+	if opts.Quantization != "" {
+		args = append(args, "--quantization", opts.Quantization)
+	}
+	if opts.MaxModelLen != nil {
+		args = append(args, "--max-model-len", fmt.Sprint(*opts.MaxModelLen))
+	}
+	if opts.GPUMemoryUtilization != "" {
+		args = append(args, "--gpu-memory-utilization", opts.GPUMemoryUtilization)
+	}
+	if opts.MaxNumSeqs != nil {
+		args = append(args, "--max-num-seqs", fmt.Sprint(*opts.MaxNumSeqs))
+	}
+	if opts.EnablePrefixCaching != nil {
+		if *opts.EnablePrefixCaching {
+			args = append(args, "--enable-prefix-caching")
 		} else {
-			return nil, fmt.Errorf("dtype must be alphanumeric")
+			args = append(args, "--no-enable-prefix-caching")
 		}
 	}
+	if opts.MaxLoRARank != nil {
+		args = append(args, "--max-lora-rank", fmt.Sprint(*opts.MaxLoRARank))
+	}
 
-	if quant, ok := v.deploymentOptions.Spec.Engine.Options[constants.QuantizationKey]; ok {
-		if utils.IsAlphanumeric(quant) {
-			container.Args = append(container.Args, "--quantization", quant)
-		} else {
-			return nil, fmt.Errorf("quantization must be alphanumeric")
+	return args
+}
+
+// addAdapters serves the model's LoRA adapters. Adapters on Hugging Face are
+// downloaded by vLLM into the same cache as the model, the others are
+// extracted into the cache before vLLM starts.
+func (v *vllmAi) addAdapters(pod *v1.PodSpec, c *v1.Container, model aimodelmap.ResolvedModel) {
+	if len(model.Spec.Adapters) == 0 {
+		return
+	}
+
+	c.Args = append(c.Args, "--enable-lora", "--lora-modules")
+	for _, a := range model.Spec.Adapters {
+		path := a.Uri
+		if isHTTP(a.Uri) {
+			path = vllmContainerVolumePath + "/adapters/" + a.Name
+			pod.InitContainers = append(pod.InitContainers, v1.Container{
+				ImagePullPolicy: v.imagePullPolicy,
+				Name:            "init-adapter-" + a.Name,
+				Image:           constants.ImageCurl,
+				Command:         []string{"sh", "-c"},
+				Args:            []string{extractOnce("$ADAPTER_PATH", "$ADAPTER_DIR", "adapter")},
+				Env: []v1.EnvVar{
+					{Name: "ADAPTER_DIR", Value: path},
+					{Name: "ADAPTER_PATH", Value: a.Uri},
+				},
+				VolumeMounts: []v1.VolumeMount{{Name: "models", MountPath: vllmContainerVolumePath}},
+			})
 		}
+		c.Args = append(c.Args, a.Name+"="+path)
 	}
+}
 
-	mergeProbe(v.deploymentOptions.Spec.Deployment.StartupProbe, container.StartupProbe)
-	mergeProbe(v.deploymentOptions.Spec.Deployment.ReadinessProbe, container.ReadinessProbe)
-	mergeProbe(v.deploymentOptions.Spec.Deployment.LivenessProbe, container.LivenessProbe)
+// vllmModelNames returns the names clients can call the model by when there
+// are several models, which can't have the typed served model name
+func vllmModelNames(m aimodelmap.ResolvedModel) []string {
+	if names := servedNames(m); names != nil {
+		return names
+	}
+
+	return []string{m.Spec.Uri}
+}
 
+// vllmServedNames returns the names vLLM serves the model under, the typed
+// option replaces the model's servedName
+func vllmServedNames(opts *a1.VLLMOptions, model aimodelmap.ResolvedModel) []string {
+	names := servedNames(model)
+	if opts.ServedModelName == "" {
+		return names
+	}
+
+	if names == nil {
+		return []string{opts.ServedModelName}
+	}
+	names[0] = opts.ServedModelName
+
+	return names
+}
+
+// addDraftModel enables speculative decoding with the draft model
+func (v *vllmAi) addDraftModel(c *v1.Container, opts *a1.VLLMOptions) {
+	if v.draftModel == nil {
+		return
+	}
+
+	tokens := int32(5)
+	if opts.NumSpeculativeTokens != nil {
+		tokens = *opts.NumSpeculativeTokens
+	}
+
+	c.Args = append(c.Args,
+		"--speculative-model", v.draftModel.Spec.Uri,
+		"--num-speculative-tokens", fmt.Sprint(tokens),
+	)
+	if opts.DraftTensorParallelSize != nil {
+		c.Args = append(c.Args, "--speculative-draft-tensor-parallel-size", fmt.Sprint(*opts.DraftTensorParallelSize))
+	}
+	if q := v.draftModel.Spec.Quantization; q != "" {
+		c.Args = append(c.Args, "--speculative-model-quantization", string(q))
+	}
+}
+
+// vllmTensorParallelSize prefers the typed option over modelTensorParallelSize
+func vllmTensorParallelSize(opts *a1.VLLMOptions, ai *a1.AIDeployment, models int) string {
+	if opts.TensorParallelSize != nil {
+		return fmt.Sprint(*opts.TensorParallelSize)
+	}
+
+	return modelTensorParallelSize(ai, models)
+}
+
+func (v *vllmAi) Port() int32 {
+	return vllmDefaultPort
+}
+
+func (v *vllmAi) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	names := make([]string, 0, len(v.models))
+	for _, m := range v.models {
+		names = append(names, m.Name)
+	}
+	v.lg.V(1).Info("Creating deployment for vllm engine", "models", names)
 	serviceAccount := false
 	replicas := int32(1)
 	if v.deploymentOptions.Spec.Deployment.Replicas != nil {
@@ -179,6 +402,7 @@ func (v *vllmAi) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 				Spec: v1.PodSpec{
 					Containers:                   []v1.Container{},
 					AutomountServiceAccountToken: &serviceAccount,
+					ImagePullSecrets:             v.imagePullSecrets,
 					Volumes: []v1.Volume{
 						{
 							Name: "models",
@@ -192,6 +416,112 @@ func (v *vllmAi) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
 		},
 	}
 
-	deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, container)
+	pod := &deployment.Spec.Template.Spec
+	addChatTemplates(a1.AIEngineNameVLLM, pod, v.models)
+
+	if len(v.models) == 1 {
+		pod.Containers = append(pod.Containers, v.modelContainer(pod, v.models[0], constants.ContainerEngineName, v.Port()))
+		return deployment, nil
+	}
+
+	// Each model has its own container and the router presents them as one
+	// endpoint
+	routes := map[string]int32{}
+	for i, m := range v.models {
+		port := modelPort(v.Port(), i)
+		pod.Containers = append(pod.Containers, v.modelContainer(pod, m, modelContainerName(i), port))
+
+		for _, name := range vllmModelNames(m) {
+			routes[name] = port
+		}
+		for _, a := range m.Spec.Adapters {
+			routes[a.Name] = port
+		}
+	}
+
+	router, err := routerContainer(v.engineImage, v.imagePullPolicy, v.Port(), routes, "/health")
+	if err != nil {
+		return nil, err
+	}
+	pod.Containers = append(pod.Containers, router)
+
 	return deployment, nil
 }
+
+// modelContainer renders the container which serves the model on the port
+func (v *vllmAi) modelContainer(pod *v1.PodSpec, model aimodelmap.ResolvedModel, name string, port int32) v1.Container {
+	healthProbeHandler := v1.ProbeHandler{
+		HTTPGet: &v1.HTTPGetAction{
+			Path: "/health",
+			Port: intstr.FromInt(int(port)),
+		},
+	}
+
+	container := v1.Container{
+		ImagePullPolicy: v.imagePullPolicy,
+		Name:            name,
+		Image:           v.engineImage,
+		Env:             v.engineEnvVars,
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: vllmContainerVolumePath,
+			},
+		},
+		Args: []string{
+			"--model", model.Spec.Uri,
+		},
+		StartupProbe: &v1.Probe{
+			InitialDelaySeconds: 3,
+			PeriodSeconds:       1,
+			FailureThreshold:    120,
+			ProbeHandler:        healthProbeHandler,
+		},
+		ReadinessProbe: &v1.Probe{
+			FailureThreshold: 3,
+			ProbeHandler:     healthProbeHandler,
+		},
+		LivenessProbe: &v1.Probe{
+			PeriodSeconds:    30,
+			TimeoutSeconds:   15,
+			FailureThreshold: 10,
+			ProbeHandler:     healthProbeHandler,
+		},
+	}
+
+	if port != vllmDefaultPort {
+		container.Args = append(container.Args, "--port", fmt.Sprint(port))
+	}
+
+	opts := vllmOptions(v.deploymentOptions, model)
+	container.Args = append(container.Args, vllmArgs(&opts)...)
+
+	// A distributed deployment sets its own parallelism
+	tp := ""
+	if dist := v.deploymentOptions.Spec.Distributed; dist != nil {
+		v.distributeLeader(&container, dist)
+	} else if tp = vllmTensorParallelSize(&opts, v.deploymentOptions, len(v.models)); tp != "" {
+		container.Args = append(container.Args, "--tensor-parallel-size", tp)
+	}
+
+	mergeProbe(v.deploymentOptions.Spec.Deployment.StartupProbe, container.StartupProbe)
+	mergeProbe(v.deploymentOptions.Spec.Deployment.ReadinessProbe, container.ReadinessProbe)
+	mergeProbe(v.deploymentOptions.Spec.Deployment.LivenessProbe, container.LivenessProbe)
+
+	if names := vllmServedNames(&opts, model); names != nil {
+		container.Args = append(append(container.Args, "--served-model-name"), names...)
+	}
+	if path := chatTemplatePath(&container, model); path != "" {
+		container.Args = append(container.Args, "--chat-template", path)
+	}
+
+	v.addAdapters(pod, &container, model)
+	v.addDraftModel(&container, &opts)
+
+	// NCCL needs more shared memory than the default when the model is split
+	if v.deploymentOptions.Spec.Distributed != nil || tp != "" {
+		sharedMemory(pod, &container)
+	}
+
+	return container
+}
@@ -0,0 +1,121 @@
+package engines
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+	"github.com/premAI-io/prem-operator/pkg/utils"
+)
+
+const vllmRayPort int32 = 6379
+
+// vllmLeaderScript starts the Ray head, waits for the workers' GPUs to join
+// the cluster and then starts vLLM with the container's args
+const vllmLeaderScript = `ray start --head --port=6379 &&
+until python3 -c "import ray, sys; ray.init(address='auto', logging_level='ERROR'); sys.exit(ray.cluster_resources().get('GPU', 0) < $RAY_GPUS)"; do sleep 5; done &&
+exec python3 -m vllm.entrypoints.openai.api_server "$@"`
+
+// vllmHostIP tells vLLM which address the other nodes can reach it on
+var vllmHostIP = v1.EnvVar{
+	Name: "VLLM_HOST_IP",
+	ValueFrom: &v1.EnvVarSource{
+		FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.podIP"},
+	},
+}
+
+// distributeLeader makes the engine container the Ray head. The model is
+// split across each node's GPUs with tensor parallelism and across the nodes
+// with pipeline parallelism.
+func (v *vllmAi) distributeLeader(c *v1.Container, dist *a1.Distributed) {
+	c.Command = []string{"sh", "-c", vllmLeaderScript, "--"}
+	c.Args = append(c.Args,
+		"--tensor-parallel-size", fmt.Sprint(dist.GPUsPerNode),
+		"--pipeline-parallel-size", fmt.Sprint(dist.Nodes),
+		"--distributed-executor-backend", "ray",
+	)
+	c.Env = append(append([]v1.EnvVar{}, c.Env...),
+		v1.EnvVar{Name: "RAY_GPUS", Value: fmt.Sprint(dist.Nodes * dist.GPUsPerNode)},
+		vllmHostIP,
+	)
+	c.Ports = append(c.Ports, v1.ContainerPort{Name: "gcs", ContainerPort: vllmRayPort})
+}
+
+func (v *vllmAi) HeadPorts() []v1.ServicePort {
+	return []v1.ServicePort{{Name: "gcs", Port: vllmRayPort}}
+}
+
+// Workers are Ray workers which join the leader's cluster. vLLM runs its
+// workers on them once the leader starts.
+func (v *vllmAi) Workers(owner metav1.Object) (*appsv1.StatefulSet, error) {
+	dist := v.deploymentOptions.Spec.Distributed
+	if dist == nil {
+		return nil, fmt.Errorf("vllm: the deployment isn't distributed")
+	}
+
+	name := resources.WorkersName(v.resourceName)
+	labels := resources.GenDefaultLabels(name)
+	replicas := dist.Nodes - 1
+	serviceAccount := false
+
+	container := v1.Container{
+		ImagePullPolicy: v.imagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           v.engineImage,
+		Command:         []string{"sh", "-c", "ray start --address=$RAY_HEAD:6379 --block"},
+		Env: append(append([]v1.EnvVar{}, v.engineEnvVars...),
+			v1.EnvVar{Name: "RAY_HEAD", Value: resources.HeadServiceName(v.resourceName)},
+			vllmHostIP,
+		),
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "models",
+				MountPath: vllmContainerVolumePath,
+			},
+		},
+	}
+
+	pod := v1.PodSpec{
+		AutomountServiceAccountToken: &serviceAccount,
+		ImagePullSecrets:             v.imagePullSecrets,
+		Volumes: []v1.Volume{
+			{
+				Name: "models",
+				VolumeSource: v1.VolumeSource{
+					EmptyDir: &v1.EmptyDirVolumeSource{},
+				},
+			},
+		},
+	}
+	sharedMemory(&pod, &container)
+	pod.Containers = []v1.Container{container}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       v.namespace,
+			Labels:          labels,
+			OwnerReferences: resources.GenOwner(owner),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         resources.HeadServiceName(v.resourceName),
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      utils.MergeMaps(labels, v.deploymentOptions.Spec.Deployment.Labels),
+					Annotations: utils.MergeMaps(v.deploymentOptions.Spec.Deployment.Annotations),
+				},
+				Spec: pod,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,440 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+)
+
+var _ = Describe("vLLM", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameVLLM},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name: "llama",
+			Spec: a1.AIModelSpec{Uri: "meta-llama/Meta-Llama-3-70B-Instruct"},
+		}}
+	})
+
+	create := func(ctx SpecContext) aideployment.MLEngine {
+		e, err := engines.Lookup(a1.AIEngineNameVLLM)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+
+		return mle
+	}
+
+	Context("tensor parallelism", func() {
+		BeforeEach(func() {
+			ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+		})
+
+		render := func(ctx SpecContext) v1.Container {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+			return d.Spec.Template.Spec.Containers[0]
+		}
+
+		It("isn't used with one GPU", func(ctx SpecContext) {
+			Expect(render(ctx).Args).NotTo(ContainElement("--tensor-parallel-size"))
+		})
+
+		It("splits the model across the requested GPUs", func(ctx SpecContext) {
+			ai.Spec.Deployment.Resources.Requests = v1.ResourceList{
+				constants.NvidiaGPULabel: resource.MustParse("4"),
+			}
+
+			c := render(ctx)
+			Expect(c.Args).To(ContainElements("--tensor-parallel-size", "4"))
+			Expect(c.VolumeMounts).To(ContainElement(HaveField("MountPath", "/dev/shm")))
+		})
+
+		It("can be overridden by the engine options", func(ctx SpecContext) {
+			ai.Spec.Deployment.Resources.Requests = v1.ResourceList{
+				constants.NvidiaGPULabel: resource.MustParse("4"),
+			}
+			ai.Spec.Engine.Options = map[string]string{constants.TensorParallelSizeKey: "2"}
+
+			Expect(render(ctx).Args).To(ContainElements("--tensor-parallel-size", "2"))
+		})
+
+		It("rejects an override which isn't a positive integer", func() {
+			ai.Spec.Engine.Options = map[string]string{constants.TensorParallelSizeKey: "all"}
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring(constants.TensorParallelSizeKey)))
+		})
+	})
+
+	Context("typed options", func() {
+		render := func(ctx SpecContext) []string {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+			return d.Spec.Template.Spec.Containers[0].Args
+		}
+
+		It("renders the options as flags", func(ctx SpecContext) {
+			maxModelLen, maxNumSeqs, prefixCaching := int32(8192), int32(64), true
+			ai.Spec.Engine.VLLM = &a1.VLLMOptions{
+				MaxModelLen:          &maxModelLen,
+				GPUMemoryUtilization: "0.85",
+				MaxNumSeqs:           &maxNumSeqs,
+				EnablePrefixCaching:  &prefixCaching,
+				ServedModelName:      "llama-3-70b",
+			}
+
+			args := render(ctx)
+			Expect(args).To(ContainElements("--max-model-len", "8192"))
+			Expect(args).To(ContainElements("--gpu-memory-utilization", "0.85"))
+			Expect(args).To(ContainElements("--max-num-seqs", "64"))
+			Expect(args).To(ContainElement("--enable-prefix-caching"))
+			Expect(args).To(ContainElements("--served-model-name", "llama-3-70b"))
+		})
+
+		It("prefers them over the engine options and the model", func(ctx SpecContext) {
+			models[0].Spec.DataType = "float16"
+			ai.Spec.Engine.Options = map[string]string{constants.QuantizationKey: "gptq"}
+			ai.Spec.Engine.VLLM = &a1.VLLMOptions{Dtype: "bfloat16", Quantization: "awq"}
+
+			args := render(ctx)
+			Expect(args).To(ContainElements("--dtype", "bfloat16"))
+			Expect(args).To(ContainElements("--quantization", "awq"))
+		})
+
+		It("falls back to the engine options and the model", func(ctx SpecContext) {
+			models[0].Spec.DataType = "float16"
+			ai.Spec.Engine.Options = map[string]string{constants.QuantizationKey: "gptq"}
+
+			args := render(ctx)
+			Expect(args).To(ContainElements("--dtype", "float16"))
+			Expect(args).To(ContainElements("--quantization", "gptq"))
+			Expect(ai.Spec.Engine.Options).To(HaveLen(1))
+		})
+
+		DescribeTable("rejects values vLLM doesn't accept",
+			func(opts map[string]string, typed *a1.VLLMOptions, msg string) {
+				ai.Spec.Engine.Options = opts
+				ai.Spec.Engine.VLLM = typed
+
+				e, err := engines.Lookup(a1.AIEngineNameVLLM)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring(msg)))
+			},
+			Entry("dtype", map[string]string{constants.DtypeKey: "int3"}, nil, "dtype"),
+			Entry("quantization", map[string]string{constants.QuantizationKey: "awq; rm"}, nil, "quantization"),
+			Entry("gpuMemoryUtilization", nil, &a1.VLLMOptions{GPUMemoryUtilization: "1.5"}, "gpuMemoryUtilization"),
+		)
+	})
+
+	Context("LoRA adapters", func() {
+		BeforeEach(func() {
+			models[0].Spec.Adapters = []a1.LoRAAdapter{
+				{Name: "sql", Uri: "acme/llama-3-sql-lora"},
+				{Name: "support", Uri: "https://models.example.com/support-lora.tar.gz"},
+			}
+		})
+
+		It("serves each adapter under its name", func(ctx SpecContext) {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			pod := d.Spec.Template.Spec
+			Expect(pod.Containers[0].Args).To(ContainElements(
+				"--enable-lora", "--lora-modules",
+				"sql=acme/llama-3-sql-lora",
+				"support=/root/.cache/huggingface/adapters/support",
+			))
+			Expect(pod.InitContainers).To(ConsistOf(And(
+				HaveField("Name", "init-adapter-support"),
+				HaveField("Env", ContainElement(v1.EnvVar{
+					Name: "ADAPTER_PATH", Value: "https://models.example.com/support-lora.tar.gz",
+				})),
+			)))
+		})
+
+		It("rejects adapters with the same name", func() {
+			models[0].Spec.Adapters[1].Name = "sql"
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("more than once")))
+		})
+	})
+
+	Context("model names and chat templates", func() {
+		BeforeEach(func() {
+			models[0].Name = "llama-3"
+			models[0].Namespace = "default"
+			models[0].Variant = "70b"
+			models[0].HostName = "llama-3-70b"
+			models[0].Spec.ServedName = "llama-3-70b-instruct"
+			models[0].Spec.Aliases = []string{"llama-3", "default"}
+			models[0].Spec.ChatTemplate = "{% for m in messages %}{{ m.content }}{% endfor %}"
+		})
+
+		It("serves the model under its names", func(ctx SpecContext) {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			c := d.Spec.Template.Spec.Containers[0]
+			Expect(c.Args).To(ContainElements("--served-model-name", "llama-3-70b-instruct", "llama-3", "default"))
+			Expect(c.Args).To(ContainElements("--chat-template", "/chat-templates/llama-3-70b.jinja"))
+			Expect(c.VolumeMounts).To(ContainElement(HaveField("MountPath", "/chat-templates")))
+
+			Expect(d.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Projected.Sources", ConsistOf(
+				HaveField("ConfigMap", And(
+					HaveField("Name", "llama-3"),
+					HaveField("Items", ConsistOf(v1.KeyToPath{
+						Key:  "vllm-70b-chatTemplate",
+						Path: "llama-3-70b.jinja",
+					})),
+				)),
+			))))
+		})
+
+		It("prefers the typed served model name", func(ctx SpecContext) {
+			ai.Spec.Engine.VLLM = &a1.VLLMOptions{ServedModelName: "llm"}
+
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--served-model-name", "llm", "llama-3", "default"))
+		})
+
+		It("rejects a chat template from an AIModelMap in another namespace", func() {
+			models[0].Namespace = "shared"
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("AIDeployment's namespace default")))
+		})
+
+		It("rejects a chat template on an inline model", func() {
+			models[0].Variant = "inline"
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("chat template")))
+		})
+	})
+
+	Context("draft model", func() {
+		BeforeEach(func() {
+			models[0].Spec.TokenizerFamily = "llama3"
+			ai.Spec.Engine.VLLM = &a1.VLLMOptions{
+				DraftModel: &a1.AIModel{AIModelSpec: a1.AIModelSpec{
+					Uri:             "meta-llama/Llama-3.2-1B-Instruct",
+					TokenizerFamily: "llama3",
+				}},
+			}
+		})
+
+		It("enables speculative decoding", func(ctx SpecContext) {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			args := d.Spec.Template.Spec.Containers[0].Args
+			Expect(args).To(ContainElements("--speculative-model", "meta-llama/Llama-3.2-1B-Instruct"))
+			Expect(args).To(ContainElements("--num-speculative-tokens", "5"))
+		})
+
+		It("resolves a draft model from an AIModelMap", func(ctx SpecContext) {
+			scheme := runtime.NewScheme()
+			Expect(a1.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&a1.AIModelMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "llama-3", Namespace: "default"},
+				Spec: a1.AIModelMapSpec{Vllm: []a1.AIModelVariant{{
+					Variant: "1b",
+					AIModelSpec: a1.AIModelSpec{
+						Uri:             "meta-llama/Llama-3.2-1B-Instruct",
+						Quantization:    "awq",
+						TokenizerFamily: "llama3",
+					},
+				}}},
+			}).Build()
+			ai.Spec.Engine.VLLM.DraftModel = &a1.AIModel{
+				ModelMapRef: &a1.AIModelMapReference{Name: "llama-3", Variant: "1b"},
+			}
+			tokens := int32(3)
+			ai.Spec.Engine.VLLM.NumSpeculativeTokens = &tokens
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			mle, err := e.Create(ctx, c, ai, models)
+			Expect(err).NotTo(HaveOccurred())
+			d, err := mle.Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			args := d.Spec.Template.Spec.Containers[0].Args
+			Expect(args).To(ContainElements("--speculative-model", "meta-llama/Llama-3.2-1B-Instruct"))
+			Expect(args).To(ContainElements("--num-speculative-tokens", "3"))
+			Expect(args).To(ContainElements("--speculative-model-quantization", "awq"))
+		})
+
+		It("rejects a draft model with another tokenizer", func(ctx SpecContext) {
+			ai.Spec.Engine.VLLM.DraftModel.TokenizerFamily = "mistral"
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = e.Create(ctx, nil, ai, models)
+			Expect(err).To(MatchError(ContainSubstring("tokenizer family mistral")))
+		})
+
+		It("only splits the draft model across one GPU or as many as the model", func(ctx SpecContext) {
+			ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+			ai.Spec.Deployment.Resources.Requests = v1.ResourceList{
+				constants.NvidiaGPULabel: resource.MustParse("4"),
+			}
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+
+			dtp := int32(2)
+			ai.Spec.Engine.VLLM.DraftTensorParallelSize = &dtp
+			_, err = e.Create(ctx, nil, ai, models)
+			Expect(err).To(MatchError(ContainSubstring("draftTensorParallelSize must be 1 or the model's tensor parallel size 4")))
+
+			dtp = 4
+			mle, err := e.Create(ctx, nil, ai, models)
+			Expect(err).NotTo(HaveOccurred())
+			d, err := mle.Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--speculative-draft-tensor-parallel-size", "4"))
+		})
+
+		It("can't be used by a distributed deployment", func() {
+			ai.Spec.Distributed = &a1.Distributed{Nodes: 2, GPUsPerNode: 1}
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("can't use a draft model")))
+		})
+	})
+
+	Context("several models", func() {
+		BeforeEach(func() {
+			ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+			ai.Spec.Deployment.Resources.Requests = v1.ResourceList{
+				constants.NvidiaGPULabel: resource.MustParse("4"),
+			}
+			models = append(models, aimodelmap.ResolvedModel{
+				Name: "mistral",
+				Spec: a1.AIModelSpec{
+					Uri:      "mistralai/Mistral-7B-Instruct-v0.3",
+					Adapters: []a1.LoRAAdapter{{Name: "sql", Uri: "acme/mistral-sql-lora"}},
+				},
+			})
+		})
+
+		It("serves each model from its own container behind the router", func(ctx SpecContext) {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			cs := d.Spec.Template.Spec.Containers
+			Expect(cs).To(HaveLen(3))
+			Expect(cs[0].Name).To(Equal(constants.ContainerModelPrefix + "0"))
+			Expect(cs[0].Args).To(ContainElements("--port", "8001"))
+			Expect(cs[0].Args).To(ContainElements("--tensor-parallel-size", "2"))
+			Expect(cs[0].ReadinessProbe.HTTPGet.Port.IntValue()).To(Equal(8001))
+			Expect(cs[1].Args).To(ContainElements("--model", "mistralai/Mistral-7B-Instruct-v0.3", "--port", "8002"))
+
+			router := cs[2]
+			Expect(router.Name).To(Equal(constants.ContainerRouterName))
+			Expect(router.Ports).To(ConsistOf(HaveField("ContainerPort", int32(8000))))
+			Expect(router.Env).To(ContainElement(v1.EnvVar{
+				Name:  "ROUTES",
+				Value: `{"meta-llama/Meta-Llama-3-70B-Instruct":8001,"mistralai/Mistral-7B-Instruct-v0.3":8002,"sql":8002}`,
+			}))
+
+			Expect(d.Spec.Template.Spec.Volumes).To(HaveLen(2))
+		})
+
+		DescribeTable("rejects what can't be split between containers",
+			func(change func(), msg string) {
+				change()
+
+				e, err := engines.Lookup(a1.AIEngineNameVLLM)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring(msg)))
+			},
+			Entry("uneven GPUs", func() {
+				ai.Spec.Deployment.Resources.Requests[constants.NvidiaGPULabel] = resource.MustParse("3")
+			}, "split evenly"),
+			Entry("a served model name", func() {
+				ai.Spec.Engine.VLLM = &a1.VLLMOptions{ServedModelName: "llm"}
+			}, "servedModelName"),
+			Entry("the same model twice", func() {
+				models[1].Spec.Uri = models[0].Spec.Uri
+			}, "more than once"),
+			Entry("the kserve workload", func() {
+				ai.Spec.Workload = a1.WorkloadKServe
+			}, "kserve"),
+		)
+	})
+
+	Context("distributed", func() {
+		BeforeEach(func() {
+			ai.Spec.Distributed = &a1.Distributed{Nodes: 2, GPUsPerNode: 8}
+		})
+
+		It("makes the engine the Ray head", func(ctx SpecContext) {
+			d, err := create(ctx).Deployment(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			c := d.Spec.Template.Spec.Containers[0]
+			Expect(c.Command[:2]).To(Equal([]string{"sh", "-c"}))
+			Expect(c.Args).To(ContainElements(
+				"--tensor-parallel-size", "8",
+				"--pipeline-parallel-size", "2",
+				"--distributed-executor-backend", "ray",
+			))
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "RAY_GPUS", Value: "16"}))
+		})
+
+		It("renders the workers", func(ctx SpecContext) {
+			dmle, ok := create(ctx).(aideployment.DistributedEngine)
+			Expect(ok).To(BeTrue())
+
+			workers, err := dmle.Workers(ai)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(workers.Name).To(Equal("llama-workers"))
+			Expect(*workers.Spec.Replicas).To(Equal(int32(1)))
+			Expect(workers.Spec.ServiceName).To(Equal("llama-head"))
+			// The workers mustn't be selected by the leader's Services
+			Expect(workers.Spec.Template.Labels).NotTo(HaveKeyWithValue(resources.DefaultLabel, "llama"))
+			Expect(workers.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				v1.EnvVar{Name: "RAY_HEAD", Value: "llama-head"},
+			))
+		})
+
+		It("only allows a single replica", func() {
+			replicas := int32(2)
+			ai.Spec.Deployment.Replicas = &replicas
+
+			e, err := engines.Lookup(a1.AIEngineNameVLLM)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("one replica")))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aideployment"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+)
+
+const (
+	whisperDefaultPort int32 = 8080
+	whisperModelsPath        = "/models"
+	// The image's entrypoint is bash -c, so the server is started directly
+	whisperServer = "/app/build/bin/whisper-server"
+	// Where OpenAI clients send audio to be transcribed
+	whisperInferencePath = "/v1/audio/transcriptions"
+)
+
+func init() {
+	Register(Engine{
+		Name:    a1.AIEngineNameWhisper,
+		Options: []string{constants.ThreadsKey},
+		ModelMapVariants: func(spec *a1.AIModelMapSpec, _ *a1.AIEngine) []a1.AIModelVariant {
+			return spec.Whisper
+		},
+		Validate: validateWhisper,
+		New: func(_ context.Context, _ ctrlClient.Client, ai *a1.AIDeployment, m []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+			return NewWhisper(ai, m)
+		},
+	})
+}
+
+// Whisper transcribes audio with the whisper.cpp server
+type Whisper struct {
+	AIDeployment *a1.AIDeployment
+	model        aimodelmap.ResolvedModel
+}
+
+func NewWhisper(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) (aideployment.MLEngine, error) {
+	if err := requireSingleModel(ai, models); err != nil {
+		return nil, err
+	}
+
+	return &Whisper{AIDeployment: ai, model: models[0]}, nil
+}
+
+func validateWhisper(ai *a1.AIDeployment, models []aimodelmap.ResolvedModel) error {
+	if err := requireSingleModel(ai, models); err != nil {
+		return err
+	}
+
+	if !isHTTP(models[0].Spec.Uri) {
+		return fmt.Errorf("whisper: the model URI must be an http(s) URL of a ggml model file")
+	}
+
+	return intOptions(ai, constants.ThreadsKey)
+}
+
+func (w *Whisper) Port() int32 {
+	return whisperDefaultPort
+}
+
+func (w *Whisper) Deployment(owner metav1.Object) (*appsv1.Deployment, error) {
+	defaults := engineDefaults(w.AIDeployment)
+	if defaults.ImageTag == "" {
+		defaults.ImageTag = constants.ImageTagWhisperCPU
+		if usesCUDA(w.AIDeployment) {
+			defaults.ImageTag = constants.ImageTagWhisperCUDA
+		}
+	}
+
+	deployment := newDeployment(w.AIDeployment, defaults)
+	pod := &deployment.Spec.Template.Spec
+
+	modelsMount := v1.VolumeMount{
+		Name:      "models",
+		MountPath: whisperModelsPath,
+	}
+	modelFile := fmt.Sprintf("%s/%s.bin", whisperModelsPath, w.model.HostName)
+
+	pod.InitContainers = append(pod.InitContainers, downloadContainer(
+		fmt.Sprintf("init-models-%s", w.AIDeployment.Name),
+		constants.ImageCurl,
+		defaults.ImagePullPolicy,
+		w.model.Spec.Uri,
+		modelFile,
+		modelsMount,
+	))
+
+	args := []string{
+		"--host", "0.0.0.0",
+		"--port", fmt.Sprint(w.Port()),
+		"--model", modelFile,
+		"--inference-path", whisperInferencePath,
+		// Accept any audio format ffmpeg can read, as the OpenAI API does
+		"--convert",
+	}
+
+	var typed *int32
+	if w.AIDeployment.Spec.Engine.Whisper != nil {
+		typed = w.AIDeployment.Spec.Engine.Whisper.Threads
+	}
+	threads := typedOption(typed, w.AIDeployment, constants.ThreadsKey)
+	if threads == "" {
+		if n := cpuThreads(w.AIDeployment.Spec.Deployment.Resources); n > 0 {
+			threads = fmt.Sprint(n)
+		}
+	}
+	if threads != "" {
+		args = append(args, "--threads", threads)
+	}
+
+	container := v1.Container{
+		ImagePullPolicy: defaults.ImagePullPolicy,
+		Name:            constants.ContainerEngineName,
+		Image:           engineImage(w.AIDeployment, defaults),
+		Env:             w.AIDeployment.Spec.Env,
+		Command:         []string{whisperServer},
+		Args:            args,
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: w.Port(),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{modelsMount},
+	}
+
+	// The server has no health route, it only listens once the model is loaded
+	setProbes(w.AIDeployment, &container, v1.ProbeHandler{
+		TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(int(w.Port()))},
+	})
+
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "models",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+
+	pod.Containers = append(pod.Containers, container)
+	finishDeployment(w.AIDeployment, owner, deployment)
+
+	return deployment, nil
+}
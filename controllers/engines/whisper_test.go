@@ -0,0 +1,77 @@
+package engines_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/aimodelmap"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/engines"
+)
+
+var _ = Describe("Whisper", func() {
+	var (
+		ai     *a1.AIDeployment
+		models []aimodelmap.ResolvedModel
+	)
+
+	BeforeEach(func() {
+		ai = &a1.AIDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "whisper", Namespace: "default"},
+			Spec: a1.AIDeploymentSpec{
+				Engine: a1.AIEngine{Name: a1.AIEngineNameWhisper},
+			},
+		}
+		models = []aimodelmap.ResolvedModel{{
+			Name:     "whisper",
+			HostName: "whisper-base-en",
+			Spec:     a1.AIModelSpec{Uri: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base.en.bin"},
+		}}
+	})
+
+	render := func(ctx SpecContext) *v1.PodSpec {
+		e, err := engines.Lookup(a1.AIEngineNameWhisper)
+		Expect(err).NotTo(HaveOccurred())
+		mle, err := e.Create(ctx, nil, ai, models)
+		Expect(err).NotTo(HaveOccurred())
+		d, err := mle.Deployment(ai)
+		Expect(err).NotTo(HaveOccurred())
+
+		return &d.Spec.Template.Spec
+	}
+
+	It("downloads the model and serves transcriptions", func(ctx SpecContext) {
+		pod := render(ctx)
+
+		Expect(pod.InitContainers).To(HaveLen(1))
+		Expect(pod.InitContainers[0].Env).To(ContainElement(v1.EnvVar{Name: "MODEL_FILE", Value: "/models/whisper-base-en.bin"}))
+
+		c := pod.Containers[0]
+		Expect(c.Image).To(Equal(constants.ImageRepositoryWhisper + ":main"))
+		Expect(c.Args).To(Equal([]string{
+			"--host", "0.0.0.0",
+			"--port", "8080",
+			"--model", "/models/whisper-base-en.bin",
+			"--inference-path", "/v1/audio/transcriptions",
+			"--convert",
+		}))
+		Expect(c.ReadinessProbe.TCPSocket.Port.IntValue()).To(Equal(8080))
+	})
+
+	It("uses the CUDA image on Nvidia GPUs", func(ctx SpecContext) {
+		ai.Spec.Deployment.Accelerator = &a1.Accelerator{Interface: a1.AcceleratorInterfaceCUDA}
+
+		Expect(render(ctx).Containers[0].Image).To(Equal(constants.ImageRepositoryWhisper + ":main-cuda"))
+	})
+
+	It("only downloads models over http", func() {
+		models[0].Spec.Uri = "openai/whisper-base.en"
+
+		e, err := engines.Lookup(a1.AIEngineNameWhisper)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Validate(ai, models)).To(MatchError(ContainSubstring("http(s)")))
+	})
+})
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+)
+
+const collectTimeout = 10 * time.Second
+
+var (
+	aiDeploymentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "aideployments"),
+		"Number of AIDeployments, by engine and status.",
+		[]string{"engine", "status"}, nil,
+	)
+
+	gpusRequestedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "gpus_requested"),
+		"Number of GPUs requested by AIDeployment workloads, by namespace.",
+		[]string{"namespace"}, nil,
+	)
+)
+
+// stateCollector reads the AIDeployments and the workloads created for them
+// from the cache each time the metrics are scraped
+type stateCollector struct {
+	client client.Reader
+}
+
+// RegisterStateCollector registers the collectors which report on the current
+// state of the cluster. It needs a client so it can't be done in init().
+func RegisterStateCollector(c client.Reader) error {
+	return crmetrics.Registry.Register(&stateCollector{client: c})
+}
+
+func (s *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- aiDeploymentsDesc
+	ch <- gpusRequestedDesc
+}
+
+func (s *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	s.collectAIDeployments(ctx, ch)
+	s.collectGPUs(ctx, ch)
+}
+
+func (s *stateCollector) collectAIDeployments(ctx context.Context, ch chan<- prometheus.Metric) {
+	type key struct {
+		engine a1.AIEngineName
+		status constants.Status
+	}
+
+	list := &a1.AIDeploymentList{}
+	if err := s.client.List(ctx, list); err != nil {
+		ch <- prometheus.NewInvalidMetric(aiDeploymentsDesc, err)
+		return
+	}
+
+	counts := map[key]int{}
+	for _, d := range list.Items {
+		counts[key{engine: d.Spec.Engine.Name, status: d.Status.Status}]++
+	}
+
+	for k, n := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			aiDeploymentsDesc, prometheus.GaugeValue, float64(n), string(k.engine), string(k.status),
+		)
+	}
+}
+
+func (s *stateCollector) collectGPUs(ctx context.Context, ch chan<- prometheus.Metric) {
+	deployments := &appsv1.DeploymentList{}
+	if err := s.client.List(ctx, deployments, client.HasLabels{resources.DefaultLabel}); err != nil {
+		ch <- prometheus.NewInvalidMetric(gpusRequestedDesc, err)
+		return
+	}
+
+	// StatefulSets hold the replicas which keep their models and the workers
+	// of distributed deployments
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := s.client.List(ctx, statefulSets, client.HasLabels{resources.DefaultLabel}); err != nil {
+		ch <- prometheus.NewInvalidMetric(gpusRequestedDesc, err)
+		return
+	}
+
+	gpus := map[string]int64{}
+	for _, d := range deployments.Items {
+		gpus[d.Namespace] += podGPUs(&d.Spec.Template.Spec) * replicaCount(d.Spec.Replicas)
+	}
+	for _, sts := range statefulSets.Items {
+		gpus[sts.Namespace] += podGPUs(&sts.Spec.Template.Spec) * replicaCount(sts.Spec.Replicas)
+	}
+
+	for ns, n := range gpus {
+		ch <- prometheus.MustNewConstMetric(gpusRequestedDesc, prometheus.GaugeValue, float64(n), ns)
+	}
+}
+
+// podGPUs is the number of GPUs the pod's containers are limited to
+func podGPUs(pod *v1.PodSpec) int64 {
+	var n int64
+	for _, c := range pod.Containers {
+		if q, ok := c.Resources.Limits[constants.NvidiaGPULabel]; ok {
+			n += q.Value()
+		}
+	}
+
+	return n
+}
+
+// replicaCount is the number of replicas, which defaults to one when unset
+func replicaCount(replicas *int32) int64 {
+	if replicas == nil {
+		return 1
+	}
+
+	return int64(*replicas)
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// downloadTracker remembers which init container runs have already been
+// recorded, so that seeing the same pod on each reconcile doesn't count
+// a download twice
+type downloadTracker struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]map[string]struct{}
+}
+
+var downloads = downloadTracker{
+	seen: map[types.NamespacedName]map[string]struct{}{},
+}
+
+// ObserveModelDownloads records the duration of finished init containers and
+// counts the failed ones for the pods belonging to an AIDeployment. This is best
+// effort, if an init container restarts several times between two calls then
+// only the last failure is counted.
+func ObserveModelDownloads(engine string, owner types.NamespacedName, pods []v1.Pod) {
+	downloads.mu.Lock()
+	defer downloads.mu.Unlock()
+
+	prev := downloads.seen[owner]
+	next := map[string]struct{}{}
+
+	observe := func(pod *v1.Pod, name string, attempt int32, st *v1.ContainerStateTerminated) {
+		key := fmt.Sprintf("%s/%s/%d", pod.UID, name, attempt)
+		next[key] = struct{}{}
+		if _, ok := prev[key]; ok {
+			return
+		}
+
+		if st.ExitCode != 0 {
+			ModelDownloadFailures.WithLabelValues(engine).Inc()
+			return
+		}
+
+		if !st.StartedAt.IsZero() && !st.FinishedAt.IsZero() {
+			ModelDownloadDuration.WithLabelValues(engine).Observe(
+				st.FinishedAt.Sub(st.StartedAt.Time).Seconds(),
+			)
+		}
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		for _, cs := range pod.Status.InitContainerStatuses {
+			if st := cs.State.Terminated; st != nil {
+				observe(pod, cs.Name, cs.RestartCount, st)
+			}
+			if st := cs.LastTerminationState.Terminated; st != nil && cs.RestartCount > 0 {
+				observe(pod, cs.Name, cs.RestartCount-1, st)
+			}
+		}
+	}
+
+	downloads.seen[owner] = next
+}
+
+// ForgetModelDownloads drops the bookkeeping for a deleted AIDeployment
+func ForgetModelDownloads(owner types.NamespacedName) {
+	downloads.mu.Lock()
+	defer downloads.mu.Unlock()
+
+	delete(downloads.seen, owner)
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "prem_operator"
+
+var (
+	// ReconcileDuration is the time taken by a single AIDeployment reconcile
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "aideployment_reconcile_duration_seconds",
+		Help:      "Time taken to reconcile an AIDeployment, by engine.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"engine"})
+
+	// ReconcileErrors counts AIDeployment reconciles which returned an error
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "aideployment_reconcile_errors_total",
+		Help:      "Number of AIDeployment reconciles which failed, by engine.",
+	}, []string{"engine"})
+
+	// ModelDownloadDuration is the run time of the init containers which
+	// download and stage models before the engine starts
+	ModelDownloadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "model_download_duration_seconds",
+		Help:      "Time taken by init containers to download models, by engine.",
+		// 1s to a little over 4.5 hours
+		Buckets: prometheus.ExponentialBuckets(1, 2, 15),
+	}, []string{"engine"})
+
+	// ModelDownloadFailures counts init containers which exited with an error
+	ModelDownloadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "model_download_failures_total",
+		Help:      "Number of failed model download init containers, by engine.",
+	}, []string{"engine"})
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(
+		ReconcileDuration,
+		ReconcileErrors,
+		ModelDownloadDuration,
+		ModelDownloadFailures,
+	)
+}
@@ -0,0 +1,176 @@
+package metrics_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	a1 "github.com/premAI-io/prem-operator/api/v1alpha1"
+	"github.com/premAI-io/prem-operator/controllers/constants"
+	"github.com/premAI-io/prem-operator/controllers/metrics"
+	"github.com/premAI-io/prem-operator/controllers/resources"
+)
+
+func terminated(exitCode int32, d time.Duration) *v1.ContainerStateTerminated {
+	start := time.Now().Add(-time.Hour)
+	return &v1.ContainerStateTerminated{
+		ExitCode:   exitCode,
+		StartedAt:  metav1.NewTime(start),
+		FinishedAt: metav1.NewTime(start.Add(d)),
+	}
+}
+
+func downloadsObserved(engine string) uint64 {
+	m := &dto.Metric{}
+	h := metrics.ModelDownloadDuration.WithLabelValues(engine).(prometheus.Histogram)
+	Expect(h.Write(m)).To(Succeed())
+	return m.GetHistogram().GetSampleCount()
+}
+
+var _ = Describe("model download metrics", func() {
+	owner := types.NamespacedName{Namespace: "default", Name: "downloads"}
+
+	AfterEach(func() {
+		metrics.ForgetModelDownloads(owner)
+	})
+
+	It("counts each init container run once", func() {
+		engine := "test-once"
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{UID: "pod-1"},
+			Status: v1.PodStatus{
+				InitContainerStatuses: []v1.ContainerStatus{{
+					Name:  "init-models",
+					State: v1.ContainerState{Terminated: terminated(0, 42*time.Second)},
+				}},
+			},
+		}
+
+		metrics.ObserveModelDownloads(engine, owner, []v1.Pod{pod})
+		metrics.ObserveModelDownloads(engine, owner, []v1.Pod{pod})
+
+		Expect(downloadsObserved(engine)).To(Equal(uint64(1)))
+		Expect(testutil.ToFloat64(metrics.ModelDownloadFailures.WithLabelValues(engine))).To(BeZero())
+	})
+
+	It("counts the failed attempts of a restarted init container", func() {
+		engine := "test-restart"
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{UID: "pod-2"},
+			Status: v1.PodStatus{
+				InitContainerStatuses: []v1.ContainerStatus{{
+					Name:                 "init-models",
+					RestartCount:         1,
+					State:                v1.ContainerState{Terminated: terminated(1, time.Second)},
+					LastTerminationState: v1.ContainerState{Terminated: terminated(1, time.Second)},
+				}},
+			},
+		}
+
+		metrics.ObserveModelDownloads(engine, owner, []v1.Pod{pod})
+		Expect(testutil.ToFloat64(metrics.ModelDownloadFailures.WithLabelValues(engine))).To(Equal(float64(2)))
+
+		pod.Status.InitContainerStatuses[0].RestartCount = 2
+		pod.Status.InitContainerStatuses[0].State = v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+		metrics.ObserveModelDownloads(engine, owner, []v1.Pod{pod})
+		Expect(testutil.ToFloat64(metrics.ModelDownloadFailures.WithLabelValues(engine))).To(Equal(float64(2)))
+	})
+})
+
+var _ = Describe("state collector", func() {
+	It("reports AIDeployments and requested GPUs", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(a1.AddToScheme(scheme)).To(Succeed())
+
+		replicas := int32(2)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&a1.AIDeployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "a"},
+				Spec:       a1.AIDeploymentSpec{Engine: a1.AIEngine{Name: a1.AIEngineNameVLLM}},
+				Status:     a1.AIDeploymentStatus{Status: constants.Ready},
+			},
+			&a1.AIDeployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "b"},
+				Spec:       a1.AIDeploymentSpec{Engine: a1.AIEngine{Name: a1.AIEngineNameVLLM}},
+				Status:     a1.AIDeploymentStatus{Status: constants.Ready},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "team-a",
+					Name:      "a",
+					Labels:    resources.GenDefaultLabels("a"),
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{{
+								Name: constants.ContainerEngineName,
+								Resources: v1.ResourceRequirements{
+									Limits: v1.ResourceList{
+										constants.NvidiaGPULabel: resource.MustParse("2"),
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "team-b",
+					Name:      "b-workers",
+					Labels:    resources.GenDefaultLabels("b-workers"),
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{{
+								Name: constants.ContainerEngineName,
+								Resources: v1.ResourceRequirements{
+									Limits: v1.ResourceList{
+										constants.NvidiaGPULabel: resource.MustParse("1"),
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+		).Build()
+
+		Expect(metrics.RegisterStateCollector(c)).To(Succeed())
+
+		expected := `
+# HELP prem_operator_aideployments Number of AIDeployments, by engine and status.
+# TYPE prem_operator_aideployments gauge
+prem_operator_aideployments{engine="vllm",status="Ready"} 2
+# HELP prem_operator_gpus_requested Number of GPUs requested by AIDeployment workloads, by namespace.
+# TYPE prem_operator_gpus_requested gauge
+prem_operator_gpus_requested{namespace="team-a"} 4
+prem_operator_gpus_requested{namespace="team-b"} 2
+`
+		Expect(testutil.GatherAndCompare(
+			crmetrics.Registry,
+			strings.NewReader(expected),
+			"prem_operator_aideployments",
+			"prem_operator_gpus_requested",
+		)).To(Succeed())
+	})
+})
@@ -22,6 +22,17 @@ func GenDefaultLabels(s string) map[string]string {
 	}
 }
 
+// WorkersName is the name of the workers of a distributed AIDeployment
+func WorkersName(s string) string {
+	return s + "-workers"
+}
+
+// HeadServiceName is the name of the headless Service the workers of a
+// distributed AIDeployment use to find the leader
+func HeadServiceName(s string) string {
+	return s + "-head"
+}
+
 func GenOwner(obj metav1.Object) []metav1.OwnerReference {
 	return []metav1.OwnerReference{
 		*metav1.NewControllerRef(obj, schema.GroupVersionKind{
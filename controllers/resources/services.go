@@ -36,3 +36,22 @@ func DesiredService(owner metav1.Object, name, namespace string, selector, label
 		},
 	}
 }
+
+// DesiredHeadlessService selects the leader of a distributed AIDeployment.
+// The workers join the leader before it is ready, so the addresses of pods
+// which aren't ready are published.
+func DesiredHeadlessService(owner metav1.Object, name, namespace string, selector map[string]string, ports []corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: GenOwner(owner),
+			Name:            name,
+			Namespace:       namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			Ports:                    ports,
+			Selector:                 selector,
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
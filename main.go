@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,6 +31,8 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -35,6 +40,10 @@ import (
 	"github.com/premAI-io/prem-operator/api/v1alpha1"
 	premlabsv1alpha1 "github.com/premAI-io/prem-operator/api/v1alpha1"
 	"github.com/premAI-io/prem-operator/controllers"
+	"github.com/premAI-io/prem-operator/controllers/config"
+	"github.com/premAI-io/prem-operator/controllers/metrics"
+	"github.com/premAI-io/prem-operator/pkg/registry"
+	"github.com/premAI-io/prem-operator/pkg/tracing"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -55,11 +64,22 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var tracingEndpoint string
+	var watchNamespaces string
+	var configFile string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&tracingEndpoint, "tracing-endpoint", "",
+		"The OTLP/HTTP endpoint traces are exported to, e.g. http://otel-collector:4318. "+
+			"Tracing is disabled when this is empty.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma separated list of namespaces the manager watches, by default it watches all namespaces. "+
+			"When set, the AutoNodeLabeler controller is disabled because it needs access to cluster scoped Nodes.")
+	flag.StringVar(&configFile, "config", "",
+		"Path of the operator config file which holds the engine defaults. It is reloaded when it changes.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -68,8 +88,35 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingEndpoint)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	// os.Exit skips deferred calls, so the exit paths after the manager has
+	// started flush the traces themselves
+	flushTraces := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			setupLog.Error(err, "problem flushing traces")
+		}
+	}
+	defer flushTraces()
+
+	namespaces := splitNamespaces(watchNamespaces)
+	cacheOpts := cache.Options{}
+	if len(namespaces) > 0 {
+		setupLog.Info("watching namespaces", "namespaces", namespaces)
+		cacheOpts.DefaultNamespaces = map[string]cache.Config{}
+		for _, ns := range namespaces {
+			cacheOpts.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOpts,
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
 		},
@@ -93,25 +140,52 @@ func main() {
 		os.Exit(1)
 	}
 
+	var configChanges <-chan event.GenericEvent
+	if configFile != "" {
+		if err = config.Load(configFile); err != nil {
+			setupLog.Error(err, "unable to load operator config", "path", configFile)
+			os.Exit(1)
+		}
+
+		watcher := config.NewWatcher(configFile)
+		if err = mgr.Add(watcher); err != nil {
+			setupLog.Error(err, "unable to watch operator config", "path", configFile)
+			os.Exit(1)
+		}
+		configChanges = watcher.Changes
+	}
+
+	if err = metrics.RegisterStateCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.AIDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        tracing.WrapClient(mgr.GetClient()),
+		Scheme:        mgr.GetScheme(),
+		APIReader:     mgr.GetAPIReader(),
+		ConfigChanges: configChanges,
+		ImageResolver: registry.NewBackoffResolver(registry.NewResolver()),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AIDeployment")
 		os.Exit(1)
 	}
 
-	controller := &controllers.AutoNodeLabelerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}
-	if err = controller.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "AutoNodeLabeler")
-		os.Exit(1)
+	if len(namespaces) == 0 {
+		controller := &controllers.AutoNodeLabelerReconciler{
+			Client: tracing.WrapClient(mgr.GetClient()),
+			Scheme: mgr.GetScheme(),
+		}
+		if err = controller.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "AutoNodeLabeler")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("AutoNodeLabeler controller is disabled when watching specific namespaces")
 	}
 
 	if err = (&controllers.AIModelMapReconciler{
-		Client: mgr.GetClient(),
+		Client: tracing.WrapClient(mgr.GetClient()),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AIModelMap")
@@ -132,6 +206,19 @@ func main() {
 
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
+		flushTraces()
 		os.Exit(1)
 	}
 }
+
+// splitNamespaces splits a comma separated list of namespaces, ignoring empty entries
+func splitNamespaces(list string) []string {
+	namespaces := []string{}
+	for _, ns := range strings.Split(list, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	return namespaces
+}
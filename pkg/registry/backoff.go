@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BackoffResolver remembers the images which failed to resolve and returns
+// the error again, without asking the registry, until the image's backoff
+// has passed. An unreachable registry then only slows down one reconcile in
+// each backoff instead of all of them.
+type BackoffResolver struct {
+	Resolver Resolver
+	// Initial is the backoff after the first failure, it doubles after each
+	// failure which follows up to Max
+	Initial time.Duration
+	Max     time.Duration
+
+	mu       sync.Mutex
+	failures map[string]failure
+}
+
+type failure struct {
+	err     error
+	backoff time.Duration
+	retry   time.Time
+}
+
+func NewBackoffResolver(r Resolver) *BackoffResolver {
+	return &BackoffResolver{
+		Resolver: r,
+		Initial:  30 * time.Second,
+		Max:      10 * time.Minute,
+	}
+}
+
+func (b *BackoffResolver) Resolve(ctx context.Context, image string, keys Keychain) (string, error) {
+	b.mu.Lock()
+	f, failed := b.failures[image]
+	b.mu.Unlock()
+
+	if failed && time.Now().Before(f.retry) {
+		return "", fmt.Errorf("%w (retrying after %s)", f.err, f.retry.Format(time.RFC3339))
+	}
+
+	digest, err := b.Resolver.Resolve(ctx, image, keys)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		delete(b.failures, image)
+		return digest, nil
+	}
+
+	backoff := b.Initial
+	if failed {
+		backoff = min(2*f.backoff, b.Max)
+	}
+
+	if b.failures == nil {
+		b.failures = map[string]failure{}
+	}
+	b.failures[image] = failure{err: err, backoff: backoff, retry: time.Now().Add(backoff)}
+
+	return "", err
+}
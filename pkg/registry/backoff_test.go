@@ -0,0 +1,73 @@
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/premAI-io/prem-operator/pkg/registry"
+)
+
+// countingResolver fails until it is told not to and counts the calls
+type countingResolver struct {
+	calls int
+	fail  bool
+}
+
+func (c *countingResolver) Resolve(_ context.Context, _ string, _ registry.Keychain) (string, error) {
+	c.calls++
+	if c.fail {
+		return "", fmt.Errorf("unreachable")
+	}
+
+	return digest, nil
+}
+
+var _ = Describe("BackoffResolver", func() {
+	It("doesn't ask the registry again until the backoff has passed", func(ctx SpecContext) {
+		res := &countingResolver{fail: true}
+		b := registry.NewBackoffResolver(res)
+		b.Initial = 100 * time.Millisecond
+
+		_, err := b.Resolve(ctx, "vllm/vllm-openai:latest", nil)
+		Expect(err).To(MatchError("unreachable"))
+
+		_, err = b.Resolve(ctx, "vllm/vllm-openai:latest", nil)
+		Expect(err).To(MatchError(ContainSubstring("retrying after")))
+		Expect(res.calls).To(Equal(1))
+
+		By("resolving other images as usual")
+		_, err = b.Resolve(ctx, "ollama/ollama:latest", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(res.calls).To(Equal(2))
+
+		By("trying again once the backoff has passed")
+		res.fail = false
+		time.Sleep(150 * time.Millisecond)
+		Expect(b.Resolve(ctx, "vllm/vllm-openai:latest", nil)).To(Equal(digest))
+		Expect(res.calls).To(Equal(3))
+	})
+
+	It("doubles the backoff up to the maximum", func(ctx SpecContext) {
+		res := &countingResolver{fail: true}
+		b := registry.NewBackoffResolver(res)
+		b.Initial = 200 * time.Millisecond
+		b.Max = 300 * time.Millisecond
+
+		_, _ = b.Resolve(ctx, "vllm/vllm-openai:latest", nil)
+		time.Sleep(240 * time.Millisecond)
+		_, _ = b.Resolve(ctx, "vllm/vllm-openai:latest", nil)
+		Expect(res.calls).To(Equal(2))
+
+		// The second backoff is capped at 300ms rather than 400ms
+		time.Sleep(240 * time.Millisecond)
+		_, _ = b.Resolve(ctx, "vllm/vllm-openai:latest", nil)
+		Expect(res.calls).To(Equal(2))
+		time.Sleep(100 * time.Millisecond)
+		_, _ = b.Resolve(ctx, "vllm/vllm-openai:latest", nil)
+		Expect(res.calls).To(Equal(3))
+	})
+})
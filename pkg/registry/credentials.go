@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Credentials are the username and password for a registry
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Keychain maps registry hosts, as in a Reference, to their credentials
+type Keychain map[string]Credentials
+
+// ParseDockerConfig reads the credentials of a docker config, the
+// .dockerconfigjson of a kubernetes.io/dockerconfigjson Secret, or the older
+// .dockercfg format which has no auths key
+func ParseDockerConfig(data []byte) (Keychain, error) {
+	type entry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+
+	cfg := struct {
+		Auths map[string]entry `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid docker config: %w", err)
+	}
+	if cfg.Auths == nil {
+		if err := json.Unmarshal(data, &cfg.Auths); err != nil {
+			return nil, fmt.Errorf("invalid docker config: %w", err)
+		}
+	}
+
+	keys := Keychain{}
+	for host, e := range cfg.Auths {
+		creds := Credentials{Username: e.Username, Password: e.Password}
+		if e.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid docker config: auth of %s: %w", host, err)
+			}
+			user, password, found := strings.Cut(string(decoded), ":")
+			if !found {
+				return nil, fmt.Errorf("invalid docker config: auth of %s isn't username:password", host)
+			}
+			creds = Credentials{Username: user, Password: password}
+		}
+
+		keys[registryHost(host)] = creds
+	}
+
+	return keys, nil
+}
+
+// Merge adds the credentials of other for the registries k doesn't have
+func (k Keychain) Merge(other Keychain) Keychain {
+	if k == nil {
+		k = Keychain{}
+	}
+	for host, creds := range other {
+		if _, ok := k[host]; !ok {
+			k[host] = creds
+		}
+	}
+
+	return k
+}
+
+// registryHost turns a docker config key, which can be a URL such as
+// https://index.docker.io/v1/, into a registry host as in a Reference
+func registryHost(key string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return DockerHub
+	}
+
+	return host
+}
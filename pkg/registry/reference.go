@@ -0,0 +1,64 @@
+package registry
+
+import "strings"
+
+// DockerHub is the registry of images which don't name one
+const DockerHub = "docker.io"
+
+// Reference is a parsed image reference such as nvcr.io/nvidia/tritonserver:24.01-py3
+type Reference struct {
+	// The registry host, which may include a port, or DockerHub
+	Registry string
+	// The repository in the registry, official Docker Hub images are in library/
+	Repository string
+	// +optional
+	Tag string
+	// +optional
+	Digest string
+}
+
+// ParseReference splits an image into its parts following the rules docker
+// uses for images without a registry. It doesn't validate the image.
+func ParseReference(image string) Reference {
+	ref := Reference{}
+
+	if name, digest, found := strings.Cut(image, "@"); found {
+		image, ref.Digest = name, digest
+	}
+
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, ref.Tag = image[:i], image[i+1:]
+	}
+
+	registry, repository, found := strings.Cut(image, "/")
+	if !found || !(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		registry, repository = DockerHub, image
+	}
+
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io":
+		registry = DockerHub
+	}
+
+	if registry == DockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	ref.Registry = registry
+	ref.Repository = repository
+
+	return ref
+}
+
+// String returns the full reference including the registry
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+
+	return s
+}
@@ -0,0 +1,162 @@
+package registry_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/premAI-io/prem-operator/pkg/registry"
+)
+
+const digest = "sha256:4bd4a1a1f14e7d2b5f5c0e3c5b6e3f3a3c1d7a8e3b6c6d2a9e8f7c6b5a4d3c2b"
+
+// fakeRegistry stands in for a registry which requires an anonymous bearer
+// token like Docker Hub does
+func fakeRegistry() *httptest.Server {
+	mux := http.NewServeMux()
+
+	var srv *httptest.Server
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("scope") != "repository:vllm/vllm-openai:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate",
+				`Bearer realm="`+srv.URL+`/token",service="fake",scope="repository:vllm/vllm-openai:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method != http.MethodHead ||
+			!strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Path != "/v2/vllm/vllm-openai/manifests/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	})
+
+	srv = httptest.NewTLSServer(mux)
+	return srv
+}
+
+// privateRegistry stands in for a registry which only gives a token to a
+// user, or with basic set asks for basic auth on the manifests instead
+func privateRegistry(basic bool) *httptest.Server {
+	mux := http.NewServeMux()
+
+	var srv *httptest.Server
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "private"})
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		switch {
+		case basic && ok && user == "user" && password == "pass":
+		case !basic && r.Header.Get("Authorization") == "Bearer private":
+		case basic:
+			w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="fake"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	})
+
+	srv = httptest.NewTLSServer(mux)
+	return srv
+}
+
+var _ = Describe("Registry", func() {
+	DescribeTable("ParseReference",
+		func(image string, expected registry.Reference) {
+			Expect(registry.ParseReference(image)).To(Equal(expected))
+		},
+		Entry("an official image", "busybox",
+			registry.Reference{Registry: "docker.io", Repository: "library/busybox"}),
+		Entry("a docker hub image with a tag", "vllm/vllm-openai:v0.4.0",
+			registry.Reference{Registry: "docker.io", Repository: "vllm/vllm-openai", Tag: "v0.4.0"}),
+		Entry("an image with a registry", "nvcr.io/nvidia/tritonserver:24.01-py3",
+			registry.Reference{Registry: "nvcr.io", Repository: "nvidia/tritonserver", Tag: "24.01-py3"}),
+		Entry("a registry with a port and a digest", "localhost:5000/foo:1@"+digest,
+			registry.Reference{Registry: "localhost:5000", Repository: "foo", Tag: "1", Digest: digest}),
+	)
+
+	It("resolves a tag to a digest", func(ctx SpecContext) {
+		srv := fakeRegistry()
+		defer srv.Close()
+
+		host := strings.TrimPrefix(srv.URL, "https://")
+		r := &registry.HTTPResolver{Client: srv.Client()}
+
+		d, err := r.Resolve(ctx, host+"/vllm/vllm-openai", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(digest))
+
+		_, err = r.Resolve(ctx, host+"/vllm/vllm-openai:missing", nil)
+		Expect(err).To(MatchError(ContainSubstring("404")))
+	})
+
+	DescribeTable("resolves a private image with the registry's credentials",
+		func(ctx SpecContext, basic bool) {
+			srv := privateRegistry(basic)
+			defer srv.Close()
+
+			host := strings.TrimPrefix(srv.URL, "https://")
+			r := &registry.HTTPResolver{Client: srv.Client()}
+
+			_, err := r.Resolve(ctx, host+"/team/engine:v1", nil)
+			Expect(err).To(HaveOccurred())
+
+			keys := registry.Keychain{host: {Username: "user", Password: "pass"}}
+			Expect(r.Resolve(ctx, host+"/team/engine:v1", keys)).To(Equal(digest))
+		},
+		Entry("with a bearer token", false),
+		Entry("with basic auth", true),
+	)
+
+	It("reads the credentials of a docker config", func() {
+		keys, err := registry.ParseDockerConfig([]byte(`{"auths": {
+			"https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("hub:secret")) + `"},
+			"nvcr.io": {"username": "$oauthtoken", "password": "key"}
+		}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(Equal(registry.Keychain{
+			registry.DockerHub: {Username: "hub", Password: "secret"},
+			"nvcr.io":          {Username: "$oauthtoken", Password: "key"},
+		}))
+
+		By("reading the older .dockercfg format")
+		keys, err = registry.ParseDockerConfig([]byte(`{"quay.io": {"username": "robot", "password": "token"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveKeyWithValue("quay.io", registry.Credentials{Username: "robot", Password: "token"}))
+	})
+
+	It("doesn't contact the registry for an image with a digest", func(ctx SpecContext) {
+		r := &registry.HTTPResolver{Client: &http.Client{}}
+
+		d, err := r.Resolve(ctx, "registry.invalid/foo@"+digest, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(digest))
+	})
+})
@@ -0,0 +1,217 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Resolver looks up the digest an image's tag currently points to
+type Resolver interface {
+	// Resolve returns the digest, e.g. sha256:..., of the image. keys has
+	// the credentials for private registries, it may be nil.
+	Resolve(ctx context.Context, image string, keys Keychain) (string, error)
+}
+
+// The manifest types which are accepted, an index is preferred so that the
+// digest is the same for every platform
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+const defaultTimeout = 10 * time.Second
+
+// HTTPResolver resolves digests with the registry HTTP API. It uses a bearer
+// token or basic auth if the registry asks for one, with the registry's
+// credentials from the keychain or else anonymously.
+type HTTPResolver struct {
+	Client *http.Client
+}
+
+func NewResolver() *HTTPResolver {
+	return &HTTPResolver{Client: &http.Client{Timeout: defaultTimeout}}
+}
+
+func (r *HTTPResolver) Resolve(ctx context.Context, image string, keys Keychain) (string, error) {
+	ref := ParseReference(image)
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	tag := ref.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	host := ref.Registry
+	if host == DockerHub {
+		host = "registry-1.docker.io"
+	}
+
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, ref.Repository, tag)
+
+	res, err := r.head(ctx, u, "")
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", image, err)
+	}
+
+	if res.StatusCode == http.StatusUnauthorized {
+		var creds *Credentials
+		if c, ok := keys[ref.Registry]; ok {
+			creds = &c
+		}
+
+		authorization, err := r.authorization(ctx, res.Header.Get("WWW-Authenticate"), creds)
+		if err != nil {
+			return "", fmt.Errorf("resolving %s: %w", image, err)
+		}
+
+		if res, err = r.head(ctx, u, authorization); err != nil {
+			return "", fmt.Errorf("resolving %s: %w", image, err)
+		}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("resolving %s: registry returned %s", image, res.Status)
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("resolving %s: registry returned no digest", image)
+	}
+
+	return digest, nil
+}
+
+func (r *HTTPResolver) head(ctx context.Context, u, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	return res, nil
+}
+
+// authorization answers the registry's challenge with the Authorization
+// header for the manifest request, creds is nil for anonymous access
+func (r *HTTPResolver) authorization(ctx context.Context, challenge string, creds *Credentials) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		token, err := r.token(ctx, params, creds)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	case strings.EqualFold(scheme, "Basic"):
+		if creds == nil {
+			return "", fmt.Errorf("the registry needs credentials, none of the pull secrets have them")
+		}
+		return "Basic " + basicAuth(creds), nil
+	}
+
+	return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+}
+
+// token gets a token from the realm in a bearer challenge's params, with the
+// credentials if there are any
+func (r *HTTPResolver) token(ctx context.Context, params string, creds *Credentials) (string, error) {
+	p := parseChallenge(params)
+	if p["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge %q has no realm", "Bearer "+params)
+	}
+
+	u, err := url.Parse(p["realm"])
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	for _, k := range []string{"service", "scope"} {
+		if p[k] != "" {
+			q.Set(k, p[k])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if creds != nil {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %s", res.Status)
+	}
+
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+
+	return body.AccessToken, nil
+}
+
+func basicAuth(creds *Credentials) string {
+	return base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+}
+
+// parseChallenge parses the comma separated key="value" pairs of a challenge
+func parseChallenge(s string) map[string]string {
+	params := map[string]string{}
+
+	for s != "" {
+		var kv string
+		// Commas can appear inside quoted values, e.g. in the scope
+		inQuote := false
+		i := 0
+		for ; i < len(s); i++ {
+			if s[i] == '"' {
+				inQuote = !inQuote
+			} else if s[i] == ',' && !inQuote {
+				break
+			}
+		}
+		kv, s = s[:i], strings.TrimPrefix(s[i:], ",")
+
+		k, v, _ := strings.Cut(strings.TrimSpace(kv), "=")
+		params[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+
+	return params
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// WrapClient returns a client which creates a span for each write made to
+// the API server. Reads are served from the cache so they are not traced.
+func WrapClient(c client.Client) client.Client {
+	return &tracingClient{Client: c}
+}
+
+type tracingClient struct {
+	client.Client
+}
+
+func (c *tracingClient) startWrite(ctx context.Context, verb string, obj client.Object) (context.Context, trace.Span) {
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+
+	return Start(
+		ctx,
+		fmt.Sprintf("%s %s", verb, kind),
+		attribute.String("k8s.namespace", obj.GetNamespace()),
+		attribute.String("k8s.name", obj.GetName()),
+	)
+}
+
+func (c *tracingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ctx, span := c.startWrite(ctx, "Create", obj)
+	err := c.Client.Create(ctx, obj, opts...)
+	End(span, err)
+
+	return err
+}
+
+func (c *tracingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, span := c.startWrite(ctx, "Update", obj)
+	err := c.Client.Update(ctx, obj, opts...)
+	End(span, err)
+
+	return err
+}
+
+func (c *tracingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, span := c.startWrite(ctx, "Patch", obj)
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	End(span, err)
+
+	return err
+}
+
+func (c *tracingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	ctx, span := c.startWrite(ctx, "Delete", obj)
+	err := c.Client.Delete(ctx, obj, opts...)
+	End(span, err)
+
+	return err
+}
+
+func (c *tracingClient) Status() client.SubResourceWriter {
+	return &tracingStatusWriter{SubResourceWriter: c.Client.Status(), client: c}
+}
+
+type tracingStatusWriter struct {
+	client.SubResourceWriter
+	client *tracingClient
+}
+
+func (w *tracingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	ctx, span := w.client.startWrite(ctx, "Update status", obj)
+	err := w.SubResourceWriter.Update(ctx, obj, opts...)
+	End(span, err)
+
+	return err
+}
+
+func (w *tracingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	ctx, span := w.client.startWrite(ctx, "Patch status", obj)
+	err := w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+	End(span, err)
+
+	return err
+}
@@ -0,0 +1,115 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/premAI-io/prem-operator/pkg/tracing"
+)
+
+// collector is a stand-in for an OpenTelemetry collector's OTLP/HTTP receiver
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer GinkgoRecover()
+
+	Expect(r.URL.Path).To(Equal("/v1/traces"))
+
+	body, err := io.ReadAll(r.Body)
+	Expect(err).ToNot(HaveOccurred())
+
+	req := &coltracepb.ExportTraceServiceRequest{}
+	Expect(proto.Unmarshal(body, req)).To(Succeed())
+
+	c.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	out, err := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	Expect(err).ToNot(HaveOccurred())
+	_, _ = w.Write(out)
+}
+
+func (c *collector) spanNamed(name string) *tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, s := range c.spans {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("tracing", func() {
+	It("is a no-op without an endpoint", func() {
+		shutdown, err := tracing.Setup(context.Background(), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shutdown(context.Background())).To(Succeed())
+	})
+
+	It("rejects endpoints which aren't http URLs", func() {
+		_, err := tracing.Setup(context.Background(), "grpc://localhost:4317")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("exports spans for the reconcile and its writes", func() {
+		col := &collector{}
+		srv := httptest.NewServer(col)
+		defer srv.Close()
+
+		shutdown, err := tracing.Setup(context.Background(), srv.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		var logged []string
+		lg := funcr.New(func(prefix, args string) {
+			logged = append(logged, args)
+		}, funcr.Options{})
+		ctx := log.IntoContext(context.Background(), lg)
+
+		c := tracing.WrapClient(fake.NewClientBuilder().Build())
+
+		ctx, span := tracing.Start(ctx, "AIDeployment.Reconcile")
+		log.FromContext(ctx).Info("reconciling")
+		err = c.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "traced"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		tracing.End(span, nil)
+
+		Expect(shutdown(context.Background())).To(Succeed())
+
+		root := col.spanNamed("AIDeployment.Reconcile")
+		Expect(root).ToNot(BeNil())
+		create := col.spanNamed("Create ConfigMap")
+		Expect(create).ToNot(BeNil())
+		Expect(create.TraceId).To(Equal(root.TraceId))
+		Expect(create.ParentSpanId).To(Equal(root.SpanId))
+
+		Expect(logged).To(ContainElement(ContainSubstring(tracing.TraceIDKey)))
+	})
+})
@@ -0,0 +1,73 @@
+#!/bin/bash -eu
+
+# Prints a Role and RoleBinding for each namespace in the comma separated list
+# given as the first argument. The rules are taken from the ClusterRole that
+# `make manifests` generates, without those for cluster scoped resources.
+
+NAMESPACES=${1:?usage: $0 <namespace,...> [operator namespace]}
+OPERATOR_NAMESPACE=${2:-prem-operator-system}
+ROOT_DIR=${ROOT_DIR:-$(dirname "$0")/..}
+
+# Nodes and AutoNodeLabelers are only used by the AutoNodeLabeler controller
+# which is disabled when the manager is given --watch-namespaces
+RULES=$(awk '
+function flush() {
+    if (block != "" && kept > 0)
+        printf "%s", block
+    block = ""
+    kept = 0
+}
+/^rules:/ { inrules = 1; next }
+!inrules { next }
+/^- / { flush(); section = "" }
+/^  resources:/ { section = "resources"; block = block $0 "\n"; next }
+/^ *[a-zA-Z]+:/ && !/^- / { section = "" }
+section == "resources" && /^  - / {
+    if ($2 ~ /^(nodes|autonodelabelers(\/[a-z]+)?)$/)
+        next
+    kept++
+}
+{ block = block $0 "\n" }
+END { flush() }
+' "$ROOT_DIR/config/rbac/role.yaml")
+
+for ns in ${NAMESPACES//,/ }; do
+cat <<EOR
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  labels:
+    app.kubernetes.io/name: role
+    app.kubernetes.io/instance: manager-role
+    app.kubernetes.io/component: rbac
+    app.kubernetes.io/created-by: prem-operator
+    app.kubernetes.io/part-of: prem-operator
+    app.kubernetes.io/managed-by: kustomize
+  name: prem-operator-manager-role
+  namespace: $ns
+rules:
+$RULES
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  labels:
+    app.kubernetes.io/name: rolebinding
+    app.kubernetes.io/instance: manager-rolebinding
+    app.kubernetes.io/component: rbac
+    app.kubernetes.io/created-by: prem-operator
+    app.kubernetes.io/part-of: prem-operator
+    app.kubernetes.io/managed-by: kustomize
+  name: prem-operator-manager-rolebinding
+  namespace: $ns
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: prem-operator-manager-role
+subjects:
+- kind: ServiceAccount
+  name: prem-operator-controller-manager
+  namespace: $OPERATOR_NAMESPACE
+EOR
+done
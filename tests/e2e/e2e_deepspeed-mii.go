@@ -111,8 +111,7 @@ var _ = Describe("deepspeed test", func() {
 				g.Expect(c.Resources.Limits["memory"]).To(Equal(resource.Quantity{}))
 				g.Expect(c.Resources.Limits["cpu"]).To(Equal(resource.Quantity{}))
 
-				g.Expect(deploymentPod.Spec.ImagePullSecrets).ToNot(BeEmpty())
-				g.Expect(deploymentPod.Spec.ImagePullSecrets[0].Name).To(Equal(constants.RegcredDockerhub))
+				g.Expect(deploymentPod.Spec.ImagePullSecrets).To(BeEmpty())
 
 				return true
 			}).WithPolling(5 * time.Second).WithTimeout(time.Minute).Should(BeTrue())
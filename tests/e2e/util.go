@@ -154,6 +154,18 @@ func createModelMapSingleEntry(name api.AIEngineName, variantName string, spec a
 		modelMap.Spec.Vllm = variants
 	case api.AIEngineNameDeepSpeedMii:
 		modelMap.Spec.DeepSpeedMii = variants
+	case api.AIEngineNameTGI:
+		modelMap.Spec.TGI = variants
+	case api.AIEngineNameOllama:
+		modelMap.Spec.Ollama = variants
+	case api.AIEngineNameLlamaCpp:
+		modelMap.Spec.LlamaCpp = variants
+	case api.AIEngineNameSGLang:
+		modelMap.Spec.SGLang = variants
+	case api.AIEngineNameTEI:
+		modelMap.Spec.Embeddings = variants
+	case api.AIEngineNameWhisper:
+		modelMap.Spec.Whisper = variants
 	}
 
 	c := getTypedClient()